@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// namespace used by every Atom 1.0 document
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// ==========
+// ATOM TYPES
+// ==========
+
+// Atom feed is one feed with information and child entries
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    AtomText    `xml:"title"`
+	Subtitle *AtomText   `xml:"subtitle"`
+	Updated  string      `xml:"updated"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+// One entry from a larger Atom feed
+type AtomEntry struct {
+	ID        string      `xml:"id"`
+	Title     AtomText    `xml:"title"`
+	Links     []AtomLink  `xml:"link"`
+	Author    *AtomPerson `xml:"author"`
+	Summary   *AtomText   `xml:"summary"`
+	Content   *AtomText   `xml:"content"`
+	Published string      `xml:"published,omitempty"`
+	Updated   string      `xml:"updated"`
+}
+
+// Atom link element, rel is "alternate" when it is not provided
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// Atom person construct, used for the author of an entry
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+// Atom text construct, can be of type text, html or xhtml
+type AtomText struct {
+	Type       string `xml:"type,attr,omitempty"`
+	Text       string `xml:",chardata"`
+	InnerXHTML string `xml:",innerxml"`
+}
+
+// ==============
+// ATOM FUNCTIONS
+// ==============
+
+// returns the text contents, keeping the markup of xhtml content.
+// optional elements that are missing are returned as empty.
+func (t *AtomText) String() string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.InnerXHTML)
+	}
+
+	return strings.TrimSpace(t.Text)
+}
+
+// finds the alternate link, preferring an html page over other types
+func alternateLink(links []AtomLink) string {
+	found := ""
+	for _, link := range links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			continue
+		}
+
+		if link.Type == "" || link.Type == "text/html" {
+			return link.Href
+		}
+
+		if found == "" {
+			found = link.Href
+		}
+	}
+
+	return found
+}
+
+// decodes an Atom document and maps it into the RSSFeed shape
+func parseAtomFeed(rawFeed []byte) (*RSSFeed, error) {
+	var atomFeed AtomFeed
+	err := xml.Unmarshal(rawFeed, &atomFeed)
+	if err != nil {
+		return &RSSFeed{}, err
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = atomFeed.Title.String()
+	feed.Channel.Link = alternateLink(atomFeed.Links)
+	feed.Channel.Description = atomFeed.Subtitle.String()
+
+	for _, entry := range atomFeed.Entries {
+		// summary is preferred, as content may be the entire article
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		// published is optional, updated is always required
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		author := ""
+		if entry.Author != nil {
+			author = strings.TrimSpace(entry.Author.Name)
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, RSSItem{
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Links),
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+			Author:      author,
+			GUID:        RSSGUID{Value: entry.ID, IsPermaLink: "false"},
+		})
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicholasss/gator/internal/database"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+// how long a session lasts before logging in again
+const sessionDuration = 30 * 24 * time.Hour
+
+// shortest password that can be set
+const minPasswordLength = 8
+
+// returned when a session token is unknown or has expired
+var errInvalidSession = errors.New("session is invalid or has expired")
+
+// shared so that consecutive password prompts read consecutive lines
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// ==============
+// AUTH FUNCTIONS
+// ==============
+
+// hashes a password with bcrypt, for storing in the users table
+func hashPassword(password string) (sql.NullString, error) {
+	if len(password) < minPasswordLength {
+		return sql.NullString{}, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(hash), Valid: true}, nil
+}
+
+// checks a password against the hash stored for a user
+func checkPassword(user database.User, password string) bool {
+	if !user.HashedPassword.Valid {
+		return false
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword.String), []byte(password))
+	return err == nil
+}
+
+// hashes a session or feed token, only the hash is stored in the database
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generates a random token, for sessions and feed tokens
+func generateToken() (string, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(rawToken), nil
+}
+
+// starts a new session for the user, and returns its token
+func createSession(ctx context.Context, s *state, userID uuid.UUID) (string, time.Time, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to generate session token: %w", err)
+	}
+
+	now := time.Now()
+	session, err := s.db.CreateSession(ctx, database.CreateSessionParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(sessionDuration),
+		UserID:    userID,
+		TokenHash: hashSessionToken(token),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to create session: %w", err)
+	}
+
+	// expired sessions are cleaned up as new ones are made
+	err = s.db.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to delete expired sessions: %w", err)
+	}
+
+	return token, session.ExpiresAt, nil
+}
+
+// returns the user of a session token.
+// errInvalidSession is returned for a missing, unknown or expired token.
+func sessionUser(ctx context.Context, s *state, token string) (database.User, error) {
+	if token == "" {
+		return database.User{}, errInvalidSession
+	}
+
+	userRecord, err := s.db.GetUserBySessionToken(ctx, database.GetUserBySessionTokenParams{
+		TokenHash: hashSessionToken(token),
+		Now:       time.Now(),
+	})
+	if err == sql.ErrNoRows {
+		return database.User{}, errInvalidSession
+	} else if err != nil {
+		return database.User{}, err
+	}
+
+	return userRecord, nil
+}
+
+// ends every session of the user except the one with keepToken, e.g. after a password change,
+// so that anyone who knew the old password is logged out. returns how many were ended.
+func endOtherSessions(ctx context.Context, s *state, userID uuid.UUID, keepToken string) (int64, error) {
+	ended, err := s.db.DeleteSessionsForUser(ctx, database.DeleteSessionsForUserParams{
+		UserID:        userID,
+		KeepTokenHash: hashSessionToken(keepToken),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete sessions: %w", err)
+	}
+
+	return ended, nil
+}
+
+// creates a read-only token for the published feed of the user, and returns it.
+// the token replaces any earlier one, which stops working.
+func createFeedToken(ctx context.Context, s *state, userID uuid.UUID) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", fmt.Errorf("unable to generate feed token: %w", err)
+	}
+
+	// like session tokens, only the hash is stored
+	err = s.db.SetFeedToken(ctx, database.SetFeedTokenParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    userID,
+		TokenHash: hashSessionToken(token),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to save feed token: %w", err)
+	}
+
+	return token, nil
+}
+
+// returns the user of a feed token.
+// errInvalidSession is returned for a missing or unknown token.
+func feedTokenUser(ctx context.Context, s *state, token string) (database.User, error) {
+	if token == "" {
+		return database.User{}, errInvalidSession
+	}
+
+	userRecord, err := s.db.GetUserByFeedToken(ctx, hashSessionToken(token))
+	if err == sql.ErrNoRows {
+		return database.User{}, errInvalidSession
+	} else if err != nil {
+		return database.User{}, err
+	}
+
+	return userRecord, nil
+}
+
+// checks if stdin is a terminal, so that questions can be answered
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// prompts for a password on stderr, without echoing it in a terminal.
+// when stdin is not a terminal, e.g. in scripts, one line is read from it.
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if isInteractive() {
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("unable to read password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// prompts for a new password twice, and returns its hash
+func readNewPassword() (sql.NullString, error) {
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	confirmation, err := readPassword("Confirm password: ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	if password != confirmation {
+		return sql.NullString{}, errors.New("passwords do not match")
+	}
+
+	return hashPassword(password)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// longest time spent finding and checking a feed
+const discoveryTimeout = 30 * time.Second
+
+// content types of the feeds that a page can link to
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+// paths where sites commonly serve their feed, tried when a page links to none
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss",
+	"/feed.xml",
+	"/rss.xml",
+	"/atom.xml",
+	"/index.xml",
+	"/feed.json",
+}
+
+// ===============
+// DISCOVERY TYPES
+// ===============
+
+// a feed found while looking at a website, the title may be empty
+type discoveredFeed struct {
+	URL   string
+	Title string
+}
+
+// ===================
+// DISCOVERY FUNCTIONS
+// ===================
+
+// fetches a document, returning its body, content type and the URL after redirects
+func fetchDocument(ctx context.Context, client *http.Client, documentURL string) ([]byte, string, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", documentURL, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	req.Header.Set("User-Agent", agent)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return nil, "", nil, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	rawDocument, err := readDocument(res.Body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rawDocument, res.Header.Get("Content-Type"), res.Request.URL, nil
+}
+
+// checks if a document is a feed in one of the supported formats, rather than a page
+func isFeedDocument(contentType string, rawDocument []byte) bool {
+	if isJSONFeed(contentType, rawDocument) {
+		return true
+	}
+
+	root, err := xmlRootElement(rawDocument)
+	if err != nil {
+		return false
+	}
+
+	switch root.Local {
+	case "rss", "feed", "RDF":
+		return true
+	}
+	return false
+}
+
+// finds the feeds linked from the head of an html page.
+// relative links are resolved against the URL of the page.
+func feedLinks(rawPage []byte, pageURL *url.URL) ([]discoveredFeed, error) {
+	doc, err := html.Parse(bytes.NewReader(rawPage))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse page: %w", err)
+	}
+
+	var feeds []discoveredFeed
+	seen := make(map[string]bool)
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "link" {
+			attrs := make(map[string]string)
+			for _, attr := range node.Attr {
+				attrs[strings.ToLower(attr.Key)] = strings.TrimSpace(attr.Val)
+			}
+
+			// rel can hold several values, e.g. "alternate home"
+			isAlternate := false
+			for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+				if rel == "alternate" {
+					isAlternate = true
+				}
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(attrs["type"])
+			if isAlternate && feedLinkTypes[mediaType] && attrs["href"] != "" {
+				href, err := pageURL.Parse(attrs["href"])
+				if err == nil && !seen[href.String()] {
+					seen[href.String()] = true
+					feeds = append(feeds, discoveredFeed{
+						URL:   href.String(),
+						Title: attrs["title"],
+					})
+				}
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return feeds, nil
+}
+
+// finds the feeds of a website.
+// a feed URL is returned as it is, pages are searched for feed links,
+// and the common feed paths of the site are tried when there are none.
+func discoverFeeds(ctx context.Context, client *http.Client, siteURL string) ([]discoveredFeed, error) {
+	rawDocument, contentType, documentURL, err := fetchDocument(ctx, client, siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch '%s': %w", siteURL, err)
+	}
+
+	if isFeedDocument(contentType, rawDocument) {
+		return []discoveredFeed{{URL: documentURL.String()}}, nil
+	}
+
+	feeds, err := feedLinks(rawDocument, documentURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(feeds) > 0 {
+		return feeds, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidateURL := documentURL.ResolveReference(&url.URL{Path: path})
+		rawCandidate, contentType, finalURL, err := fetchDocument(ctx, client, candidateURL.String())
+		if err != nil {
+			continue
+		}
+
+		if isFeedDocument(contentType, rawCandidate) {
+			return []discoveredFeed{{URL: finalURL.String()}}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no feeds were found at '%s'", siteURL)
+}
+
+// asks which feed to use when a site has several.
+// without a terminal to ask, the first feed is used.
+func chooseFeed(feeds []discoveredFeed) discoveredFeed {
+	if len(feeds) == 1 {
+		return feeds[0]
+	}
+
+	fmt.Printf("Found %d feeds:\n", len(feeds))
+	for i, feed := range feeds {
+		title := feed.Title
+		if title == "" {
+			title = "untitled"
+		}
+		fmt.Printf(" %d. %s (%s)\n", i+1, title, feed.URL)
+	}
+
+	if !isInteractive() {
+		fmt.Println("Using the first feed.")
+		return feeds[0]
+	}
+
+	for {
+		fmt.Printf("Which feed should be added? [1-%d]: ", len(feeds))
+		answer, err := stdinReader.ReadString('\n')
+		if err != nil {
+			fmt.Println("\nUsing the first feed.")
+			return feeds[0]
+		}
+
+		var choice int
+		_, err = fmt.Sscanf(strings.TrimSpace(answer), "%d", &choice)
+		if err == nil && choice >= 1 && choice <= len(feeds) {
+			return feeds[choice-1]
+		}
+	}
+}
+
+// finds the feed of a website and fetches it once, to check that it can be parsed
+func resolveFeed(ctx context.Context, client *http.Client, siteURL string, choose func([]discoveredFeed) discoveredFeed) (discoveredFeed, *RSSFeed, error) {
+	feeds, err := discoverFeeds(ctx, client, siteURL)
+	if err != nil {
+		return discoveredFeed{}, nil, err
+	}
+
+	chosen := choose(feeds)
+	feed, _, err := fetchFeed(ctx, client, chosen.URL, feedCache{})
+	if err != nil {
+		return discoveredFeed{}, nil, fmt.Errorf("unable to read the feed at '%s': %w", chosen.URL, err)
+	}
+
+	return chosen, feed, nil
+}
+
+// returns the name of a feed, from its title, falling back to its URL
+func feedName(feed *RSSFeed, discovered discoveredFeed) string {
+	if title := strings.TrimSpace(feed.Channel.Title); title != "" {
+		return title
+	}
+	if title := strings.TrimSpace(discovered.Title); title != "" {
+		return title
+	}
+
+	return discovered.URL
+}
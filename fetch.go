@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// longest time spent on a single request for a feed or page, including redirects
+const fetchTimeout = 30 * time.Second
+
+// largest feed or page that is read, larger documents are refused
+const maxDocumentSize = 10 << 20
+
+// returned when a URL given through the API points at a non-public address
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// fetches feeds and pages from URLs given on the command line
+var feedClient = &http.Client{Timeout: fetchTimeout}
+
+// fetches feeds and pages from URLs given by API callers, which could otherwise
+// make the server request its own network. addresses are checked as they are
+// dialed, so redirects and DNS answers cannot reach those networks either.
+// agg uses it for every fetch of a feed added through the API.
+var publicFeedClient = newPublicClient()
+
+// ===============
+// FETCH FUNCTIONS
+// ===============
+
+// creates a client that only connects to public addresses, and never through a proxy
+func newPublicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: fetchTimeout,
+		Control: checkPublicAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: transport,
+	}
+}
+
+// refuses connections to loopback, private, link-local and other non-public addresses
+func checkPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return errNonPublicAddress
+	}
+
+	// shared address space used by carrier-grade NAT, which IsPrivate does not cover
+	if netip.MustParsePrefix("100.64.0.0/10").Contains(ip) {
+		return errNonPublicAddress
+	}
+
+	return nil
+}
+
+// reads a response body, refusing documents larger than maxDocumentSize
+func readDocument(body io.Reader) ([]byte, error) {
+	rawDocument, err := io.ReadAll(io.LimitReader(body, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rawDocument) > maxDocumentSize {
+		return nil, fmt.Errorf("document is larger than %d bytes", maxDocumentSize)
+	}
+
+	return rawDocument, nil
+}
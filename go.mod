@@ -3,6 +3,11 @@ module github.com/nicholasss/gator
 go 1.23.1
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.40.0
+	golang.org/x/net v0.42.0
+	golang.org/x/term v0.33.0
 )
+
+require golang.org/x/sys v0.34.0 // indirect
@@ -5,9 +5,23 @@ import (
 	"os"
 )
 
+// number of consecutive failures before a feed is disabled,
+// used when the config file does not specify one
+const defaultMaxFeedFailures = 10
+
 type Config struct {
 	DBURL           string `json:"db_url"`
-	CurrentUsername string `json:"current_user_name"`
+	SessionToken    string `json:"session_token,omitempty"`
+	MaxFeedFailures int    `json:"max_feed_failures,omitempty"`
+}
+
+// returns the number of consecutive failures before a feed is disabled
+func (c Config) FeedFailureLimit() int {
+	if c.MaxFeedFailures <= 0 {
+		return defaultMaxFeedFailures
+	}
+
+	return c.MaxFeedFailures
 }
 
 // function to return the path of the config file
@@ -41,20 +55,27 @@ func Read() (Config, error) {
 	return loadedConfig, nil
 }
 
-// writes config to file after setting current user
-func (c Config) SetUser(username string) error {
+// writes config to file after setting the session token of the current user.
+// an empty token logs out, the file is only readable by its owner.
+func (c *Config) SetSession(token string) error {
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 
-	c.CurrentUsername = username
+	c.SessionToken = token
 	configData, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(configPath, configData, 0644)
+	err = os.WriteFile(configPath, configData, 0600)
+	if err != nil {
+		return err
+	}
+
+	// files written before sessions were added may be readable by others
+	err = os.Chmod(configPath, 0600)
 	if err != nil {
 		return err
 	}
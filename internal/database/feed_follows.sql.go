@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,9 +20,9 @@ insert into feed_follows (
 	) values (
 		$1, $2, $3, $4, $5
 	)
-	returning id, created_at, updated_at, user_id, feed_id
+	returning id, created_at, updated_at, user_id, feed_id, folder_id
 ) select
-	inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+	inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder_id,
 	feeds.name as feed_name,
 	users.name as user_name
 from inserted_feed_follow
@@ -45,6 +46,7 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
 	FeedName  string
 	UserName  string
 }
@@ -64,6 +66,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -94,27 +97,60 @@ func (q *Queries) DeleteFeedFollowForUserURL(ctx context.Context, arg DeleteFeed
 	return i, err
 }
 
+const getFeedFollow = `-- name: GetFeedFollow :one
+select id, created_at, updated_at, user_id, feed_id, folder_id from feed_follows
+where user_id = $1 and feed_id = $2
+limit 1
+`
+
+type GetFeedFollowParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+	)
+	return i, err
+}
+
 const getFeedFollowForUser = `-- name: GetFeedFollowForUser :many
 select 
-	feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+	feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder_id,
 	feeds.name as feed_name,
-	users.name as user_name
+	feeds.url as feed_url,
+	users.name as user_name,
+	folders.name as folder_name
 from feed_follows
 inner join users
 	on feed_follows.user_id = users.id
 	and feed_follows.user_id = $1
 inner join feeds
 	on feed_follows.feed_id = feeds.id
+left join folders
+	on feed_follows.folder_id = folders.id
+order by folders.name asc nulls first, feeds.name asc
 `
 
 type GetFeedFollowForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	FolderID   uuid.NullUUID
+	FeedName   string
+	FeedUrl    string
+	UserName   string
+	FolderName sql.NullString
 }
 
 func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowForUserRow, error) {
@@ -132,8 +168,11 @@ func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
+			&i.FolderName,
 		); err != nil {
 			return nil, err
 		}
@@ -147,3 +186,30 @@ func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([
 	}
 	return items, nil
 }
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :execrows
+update feed_follows
+set folder_id = $3,
+	updated_at = $4
+where user_id = $1 and feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -15,29 +15,33 @@ import (
 
 const createFeed = `-- name: CreateFeed :one
 insert into feeds (
-	id, name, created_at, updated_at, url, user_id
+	id, name, created_at, updated_at, url, user_id, fetch_interval, public_only
 ) values (
-	$1, $2, $3, $4, $5, $6
+	$1, $2, $3, $4, $5, $6, $7, $8
 	)
-returning id, name, created_at, updated_at, url, user_id
+returning id, name, created_at, updated_at, url, user_id, fetch_interval, public_only
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Url       string
-	UserID    uuid.UUID
+	ID            uuid.UUID
+	Name          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Url           string
+	UserID        uuid.UUID
+	FetchInterval sql.NullInt32
+	PublicOnly    bool
 }
 
 type CreateFeedRow struct {
-	ID        uuid.UUID
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Url       string
-	UserID    uuid.UUID
+	ID            uuid.UUID
+	Name          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Url           string
+	UserID        uuid.UUID
+	FetchInterval sql.NullInt32
+	PublicOnly    bool
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (CreateFeedRow, error) {
@@ -48,6 +52,8 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (CreateF
 		arg.UpdatedAt,
 		arg.Url,
 		arg.UserID,
+		arg.FetchInterval,
+		arg.PublicOnly,
 	)
 	var i CreateFeedRow
 	err := row.Scan(
@@ -57,12 +63,60 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (CreateF
 		&i.UpdatedAt,
 		&i.Url,
 		&i.UserID,
+		&i.FetchInterval,
+		&i.PublicOnly,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+delete from feeds
+where id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const disableFeed = `-- name: DisableFeed :exec
+update feeds
+set disabled = true,
+	updated_at = $2
+where id = $1
+`
+
+type DisableFeedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) DisableFeed(ctx context.Context, arg DisableFeedParams) error {
+	_, err := q.db.ExecContext(ctx, disableFeed, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const enableFeed = `-- name: EnableFeed :exec
+update feeds
+set disabled = false,
+	consecutive_failures = 0,
+	next_fetch_at = null,
+	updated_at = $2
+where id = $1
+`
+
+type EnableFeedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) EnableFeed(ctx context.Context, arg EnableFeedParams) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, arg.ID, arg.UpdatedAt)
+	return err
+}
+
 const getAllFeeds = `-- name: GetAllFeeds :many
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
 `
 
 func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
@@ -82,6 +136,68 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Disabled,
+			&i.NextFetchAt,
+			&i.FetchInterval,
+			&i.Ttl,
+			&i.SkipHours,
+			&i.SkipDays,
+			&i.PublicOnly,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFailingFeeds = `-- name: GetFailingFeeds :many
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
+	where consecutive_failures > 0
+	or disabled = true
+	order by consecutive_failures desc, last_error_at desc
+`
+
+func (q *Queries) GetFailingFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFailingFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Disabled,
+			&i.NextFetchAt,
+			&i.FetchInterval,
+			&i.Ttl,
+			&i.SkipHours,
+			&i.SkipDays,
+			&i.PublicOnly,
 		); err != nil {
 			return nil, err
 		}
@@ -97,7 +213,7 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getFeedByID = `-- name: GetFeedByID :one
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
 	where id = $1
 	limit 1
 `
@@ -113,12 +229,24 @@ func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.NextFetchAt,
+		&i.FetchInterval,
+		&i.Ttl,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.PublicOnly,
 	)
 	return i, err
 }
 
 const getFeedByName = `-- name: GetFeedByName :one
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
 	where name = $1
 	limit 1
 `
@@ -134,12 +262,24 @@ func (q *Queries) GetFeedByName(ctx context.Context, name string) (Feed, error)
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.NextFetchAt,
+		&i.FetchInterval,
+		&i.Ttl,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.PublicOnly,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
 	where url = $1
 	limit 1
 `
@@ -155,12 +295,24 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.NextFetchAt,
+		&i.FetchInterval,
+		&i.Ttl,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.PublicOnly,
 	)
 	return i, err
 }
 
 const getFeedsByUser = `-- name: GetFeedsByUser :many
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
 	where user_id = $1
 `
 
@@ -181,6 +333,18 @@ func (q *Queries) GetFeedsByUser(ctx context.Context, userID uuid.UUID) ([]Feed,
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Disabled,
+			&i.NextFetchAt,
+			&i.FetchInterval,
+			&i.Ttl,
+			&i.SkipHours,
+			&i.SkipDays,
+			&i.PublicOnly,
 		); err != nil {
 			return nil, err
 		}
@@ -196,13 +360,16 @@ func (q *Queries) GetFeedsByUser(ctx context.Context, userID uuid.UUID) ([]Feed,
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-select id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+select id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_error_at, disabled, next_fetch_at, fetch_interval, ttl, skip_hours, skip_days, public_only from feeds
+	where disabled = false
+	and (next_fetch_at is null or next_fetch_at <= $1)
 	order by last_fetched_at asc nulls first
 	limit 1
+	for update skip locked
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+func (q *Queries) GetNextFeedToFetch(ctx context.Context, nextFetchAt sql.NullTime) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getNextFeedToFetch, nextFetchAt)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -212,23 +379,186 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.NextFetchAt,
+		&i.FetchInterval,
+		&i.Ttl,
+		&i.SkipHours,
+		&i.SkipDays,
+		&i.PublicOnly,
 	)
 	return i, err
 }
 
+const markFeedFailed = `-- name: MarkFeedFailed :exec
+update feeds
+set consecutive_failures = consecutive_failures + 1,
+	last_error = $2,
+	last_error_at = $3,
+	next_fetch_at = $4
+where id = $1
+`
+
+type MarkFeedFailedParams struct {
+	ID          uuid.UUID
+	LastError   sql.NullString
+	LastErrorAt sql.NullTime
+	NextFetchAt sql.NullTime
+}
+
+func (q *Queries) MarkFeedFailed(ctx context.Context, arg MarkFeedFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFailed,
+		arg.ID,
+		arg.LastError,
+		arg.LastErrorAt,
+		arg.NextFetchAt,
+	)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 update feeds
 set last_fetched_at = $2,
-	updated_at = $2
+	updated_at = $2,
+	next_fetch_at = $3
 where id = $1
 `
 
 type MarkFeedFetchedParams struct {
 	ID            uuid.UUID
 	LastFetchedAt sql.NullTime
+	NextFetchAt   sql.NullTime
 }
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.ID, arg.LastFetchedAt)
+	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.ID, arg.LastFetchedAt, arg.NextFetchAt)
+	return err
+}
+
+const markFeedSucceeded = `-- name: MarkFeedSucceeded :exec
+update feeds
+set consecutive_failures = 0,
+	next_fetch_at = $2
+where id = $1
+`
+
+type MarkFeedSucceededParams struct {
+	ID          uuid.UUID
+	NextFetchAt sql.NullTime
+}
+
+func (q *Queries) MarkFeedSucceeded(ctx context.Context, arg MarkFeedSucceededParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedSucceeded, arg.ID, arg.NextFetchAt)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :exec
+update feeds
+set name = $2,
+	updated_at = $3
+where id = $1
+`
+
+type RenameFeedParams struct {
+	ID        uuid.UUID
+	Name      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.ID, arg.Name, arg.UpdatedAt)
+	return err
+}
+
+const setFeedCacheHeaders = `-- name: SetFeedCacheHeaders :exec
+update feeds
+set etag = $2,
+	last_modified = $3
+where id = $1
+`
+
+type SetFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) SetFeedCacheHeaders(ctx context.Context, arg SetFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedCacheHeaders, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const setFeedInterval = `-- name: SetFeedInterval :exec
+update feeds
+set fetch_interval = $2,
+	updated_at = $3
+where id = $1
+`
+
+type SetFeedIntervalParams struct {
+	ID            uuid.UUID
+	FetchInterval sql.NullInt32
+	UpdatedAt     time.Time
+}
+
+func (q *Queries) SetFeedInterval(ctx context.Context, arg SetFeedIntervalParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedInterval, arg.ID, arg.FetchInterval, arg.UpdatedAt)
+	return err
+}
+
+const setFeedScheduleHints = `-- name: SetFeedScheduleHints :exec
+update feeds
+set ttl = $2,
+	skip_hours = $3,
+	skip_days = $4
+where id = $1
+`
+
+type SetFeedScheduleHintsParams struct {
+	ID        uuid.UUID
+	Ttl       sql.NullInt32
+	SkipHours sql.NullString
+	SkipDays  sql.NullString
+}
+
+func (q *Queries) SetFeedScheduleHints(ctx context.Context, arg SetFeedScheduleHintsParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedScheduleHints,
+		arg.ID,
+		arg.Ttl,
+		arg.SkipHours,
+		arg.SkipDays,
+	)
+	return err
+}
+
+const setFeedURL = `-- name: SetFeedURL :exec
+update feeds
+set url = $2,
+	updated_at = $3,
+	etag = null,
+	last_modified = null,
+	consecutive_failures = 0,
+	last_error = null,
+	last_error_at = null,
+	next_fetch_at = null,
+	ttl = null,
+	skip_hours = null,
+	skip_days = null
+where id = $1
+`
+
+type SetFeedURLParams struct {
+	ID        uuid.UUID
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedURL(ctx context.Context, arg SetFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedURL, arg.ID, arg.Url, arg.UpdatedAt)
 	return err
 }
@@ -13,37 +13,156 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-insert into posts (
-	id, created_at, updated_at, title, url, description, published_at, feed_id
-) values (
-	$1, $2, $3, $4, $5, $6, $7, $8
-) returning id, created_at, updated_at, title, url, description, published_at, feed_id
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink,
+	feeds.name as feed_name,
+	coalesce(user_posts.read, false) as read,
+	coalesce(user_posts.starred, false) as starred
+	from posts
+	inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+	inner join feeds
+	on feeds.id = posts.feed_id
+	left join user_posts
+	on user_posts.post_id = posts.id
+	and user_posts.user_id = feed_follows.user_id
+	where feed_follows.user_id = $1
+	and ($2::boolean or coalesce(user_posts.read, false) = false)
+	and ($3::uuid is null or posts.feed_id = $3::uuid)
+	and ($4::uuid is null or feed_follows.folder_id = $4::uuid)
+	and ($5::timestamp is null or posts.published_at >= $5::timestamp)
+	and ($6::timestamp is null or posts.published_at < $6::timestamp)
+	order by
+		case when $7::boolean then posts.published_at end asc nulls last,
+		case when not $7::boolean then posts.published_at end desc nulls last,
+		posts.id
+	limit $8
+	offset $9
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+type BrowsePostsForUserParams struct {
+	UserID      uuid.UUID
+	IncludeRead bool
+	FeedID      uuid.NullUUID
+	FolderID    uuid.NullUUID
+	Since       sql.NullTime
+	Until       sql.NullTime
+	OldestFirst bool
+	PostLimit   int64
+	PostOffset  int64
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
+type BrowsePostsForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Author          sql.NullString
+	Guid            string
+	GuidIsPermalink sql.NullBool
+	FeedName        string
+	Read            bool
+	Starred         bool
+}
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.UserID,
+		arg.IncludeRead,
 		arg.FeedID,
+		arg.FolderID,
+		arg.Since,
+		arg.Until,
+		arg.OldestFirst,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.FeedName,
+			&i.Read,
+			&i.Starred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostForUser = `-- name: GetPostForUser :one
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink
+	from posts
+	where posts.id = $1
+	and (
+		exists (
+			select 1 from feed_follows
+			where feed_follows.feed_id = posts.feed_id
+			and feed_follows.user_id = $2
+		)
+		or exists (
+			select 1 from user_posts
+			where user_posts.post_id = posts.id
+			and user_posts.user_id = $2
+			and user_posts.starred = true
+		)
 	)
-	var i Post
+	limit 1
+`
+
+type GetPostForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetPostForUserRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Author          sql.NullString
+	Guid            string
+	GuidIsPermalink sql.NullBool
+}
+
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (GetPostForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.ID, arg.UserID)
+	var i GetPostForUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -53,43 +172,55 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Author,
+		&i.Guid,
+		&i.GuidIsPermalink,
 	)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-select posts.id, posts.created_at, posts.updated_at, title, url, description, published_at, posts.feed_id, feed_follows.id, feed_follows.created_at, feed_follows.updated_at, user_id, feed_follows.feed_id
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink,
+	coalesce(user_posts.read, false) as read,
+	coalesce(user_posts.starred, false) as starred
 	from posts
 	inner join feed_follows
 	on feed_follows.feed_id = posts.feed_id
+	left join user_posts
+	on user_posts.post_id = posts.id
+	and user_posts.user_id = feed_follows.user_id
 	where feed_follows.user_id = $1
-	order by published_at desc
-	limit $2
+	and ($2::boolean or coalesce(user_posts.read, false) = false)
+	order by posts.published_at desc nulls last
+	limit $3
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int64
+	UserID      uuid.UUID
+	IncludeRead bool
+	PostLimit   int64
 }
 
 type GetPostsForUserRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	ID_2        uuid.UUID
-	CreatedAt_2 time.Time
-	UpdatedAt_2 time.Time
-	UserID      uuid.UUID
-	FeedID_2    uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Author          sql.NullString
+	Guid            string
+	GuidIsPermalink sql.NullBool
+	Read            bool
+	Starred         bool
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.IncludeRead, arg.PostLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -106,11 +237,11 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
-			&i.ID_2,
-			&i.CreatedAt_2,
-			&i.UpdatedAt_2,
-			&i.UserID,
-			&i.FeedID_2,
+			&i.Author,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.Read,
+			&i.Starred,
 		); err != nil {
 			return nil, err
 		}
@@ -124,3 +255,160 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+select posts.id, posts.title, posts.url, posts.published_at,
+	feeds.name as feed_name,
+	ts_rank(posts.search_vector, search_query) as rank,
+	ts_headline('english', coalesce(posts.description, posts.title), search_query,
+		'StartSel=[, StopSel=], MaxFragments=2, MaxWords=25, MinWords=10') as snippet
+	from posts
+	inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+	inner join feeds
+	on feeds.id = posts.feed_id,
+	websearch_to_tsquery('english', $1) as search_query
+	where feed_follows.user_id = $2
+	and posts.search_vector @@ search_query
+	order by rank desc, posts.published_at desc nulls last
+	limit $3
+`
+
+type SearchPostsForUserParams struct {
+	SearchTerms string
+	UserID      uuid.UUID
+	PostLimit   int64
+}
+
+type SearchPostsForUserRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt sql.NullTime
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.SearchTerms, arg.UserID, arg.PostLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostGUIDByURL = `-- name: SetPostGUIDByURL :execrows
+update posts
+set guid = $1,
+	guid_is_permalink = $2
+where posts.feed_id = $3
+and posts.url = $4
+and posts.guid = posts.url
+and not exists (
+	select 1 from posts as known
+	where known.feed_id = $3
+	and known.guid = $1
+)
+`
+
+type SetPostGUIDByURLParams struct {
+	Guid            string
+	GuidIsPermalink sql.NullBool
+	FeedID          uuid.UUID
+	Url             string
+}
+
+func (q *Queries) SetPostGUIDByURL(ctx context.Context, arg SetPostGUIDByURLParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setPostGUIDByURL,
+		arg.Guid,
+		arg.GuidIsPermalink,
+		arg.FeedID,
+		arg.Url,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertPost = `-- name: UpsertPost :one
+insert into posts (
+	id, created_at, updated_at, title, url, description, published_at, feed_id, author,
+	guid, guid_is_permalink
+) values (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+on conflict (feed_id, guid) do update
+set title = excluded.title,
+	url = excluded.url,
+	description = excluded.description,
+	published_at = coalesce(excluded.published_at, posts.published_at),
+	author = excluded.author,
+	guid_is_permalink = excluded.guid_is_permalink,
+	updated_at = excluded.updated_at
+where (posts.title, posts.url, posts.description, posts.author)
+	is distinct from (excluded.title, excluded.url, excluded.description, excluded.author)
+returning id, (xmax = 0)::boolean as inserted
+`
+
+type UpsertPostParams struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     sql.NullString
+	PublishedAt     sql.NullTime
+	FeedID          uuid.UUID
+	Author          sql.NullString
+	Guid            string
+	GuidIsPermalink sql.NullBool
+}
+
+type UpsertPostRow struct {
+	ID       uuid.UUID
+	Inserted bool
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Author,
+		arg.Guid,
+		arg.GuidIsPermalink,
+	)
+	var i UpsertPostRow
+	err := row.Scan(&i.ID, &i.Inserted)
+	return i, err
+}
@@ -7,45 +7,70 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countUsers = `-- name: CountUsers :one
+select count(*) from users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 insert into users (
-	id, created_at, updated_at, name
+	id, created_at, updated_at, name, hashed_password, is_admin
 ) values (
-	$1, $2, $3, $4
-) returning id, created_at, updated_at, name
+	$1, $2, $3, $4, $5, $6
+) returning id, created_at, updated_at, name, is_admin
 `
 
 type CreateUserParams struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	HashedPassword sql.NullString
+	IsAdmin        bool
+}
+
+type CreateUserRow struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Name      string
+	IsAdmin   bool
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
 	row := q.db.QueryRowContext(ctx, createUser,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Name,
+		arg.HashedPassword,
+		arg.IsAdmin,
 	)
-	var i User
+	var i CreateUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-select id, created_at, updated_at, name from users
+select id, created_at, updated_at, name, hashed_password, is_admin from users
 	where id = $1
 	limit 1
 `
@@ -58,12 +83,14 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.HashedPassword,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
 const getUserByName = `-- name: GetUserByName :one
-select id, created_at, updated_at, name from users
+select id, created_at, updated_at, name, hashed_password, is_admin from users
 	where name = $1
 	limit 1
 `
@@ -76,12 +103,14 @@ func (q *Queries) GetUserByName(ctx context.Context, name string) (User, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.HashedPassword,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-select id, created_at, updated_at, name from users
+select id, created_at, updated_at, name, hashed_password, is_admin from users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -98,6 +127,8 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.HashedPassword,
+			&i.IsAdmin,
 		); err != nil {
 			return nil, err
 		}
@@ -120,3 +151,39 @@ func (q *Queries) ResetUsers(ctx context.Context) error {
 	_, err := q.db.ExecContext(ctx, resetUsers)
 	return err
 }
+
+const setUserAdmin = `-- name: SetUserAdmin :exec
+update users
+	set is_admin = $2,
+	updated_at = $3
+	where id = $1
+`
+
+type SetUserAdminParams struct {
+	ID        uuid.UUID
+	IsAdmin   bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAdmin, arg.ID, arg.IsAdmin, arg.UpdatedAt)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+update users
+	set hashed_password = $2,
+	updated_at = $3
+	where id = $1
+`
+
+type SetUserPasswordParams struct {
+	ID             uuid.UUID
+	HashedPassword sql.NullString
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.ID, arg.HashedPassword, arg.UpdatedAt)
+	return err
+}
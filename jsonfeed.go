@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// prefix of the version url used by JSON Feed 1.0 and 1.1 documents
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+// ===============
+// JSON FEED TYPES
+// ===============
+
+// JSON feed is one feed with information and child items
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// One item from a larger JSON feed
+type JSONFeedItem struct {
+	ID            any    `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// ===================
+// JSON FEED FUNCTIONS
+// ===================
+
+// checks the content type header, then the document itself, for a JSON feed
+func isJSONFeed(contentType string, rawFeed []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		switch mediaType {
+		case "application/feed+json", "application/json":
+			return true
+		}
+	}
+
+	return bytes.HasPrefix(bytes.TrimSpace(rawFeed), []byte("{"))
+}
+
+// returns the items permalink, falling back to an id that is a url
+func (item JSONFeedItem) link() string {
+	if item.URL != "" {
+		return item.URL
+	}
+	if item.ExternalURL != "" {
+		return item.ExternalURL
+	}
+
+	// ids are strings, but some 1.0 feeds use numbers
+	id, ok := item.ID.(string)
+	if ok && (strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://")) {
+		return id
+	}
+
+	return ""
+}
+
+// returns the items id as a string, formatting numeric ids
+func (item JSONFeedItem) id() string {
+	switch id := item.ID.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+// decodes a JSON feed document and maps it into the RSSFeed shape
+func parseJSONFeed(rawFeed []byte) (*RSSFeed, error) {
+	var jsonFeed JSONFeed
+	err := json.Unmarshal(rawFeed, &jsonFeed)
+	if err != nil {
+		return &RSSFeed{}, err
+	}
+
+	if !strings.HasPrefix(jsonFeed.Version, jsonFeedVersionPrefix) {
+		return &RSSFeed{}, fmt.Errorf("unknown json feed version: '%s'", jsonFeed.Version)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = jsonFeed.Title
+	feed.Channel.Link = jsonFeed.HomePageURL
+	feed.Channel.Description = jsonFeed.Description
+
+	for _, item := range jsonFeed.Items {
+		// summary is preferred, as content may be the entire article
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, RSSItem{
+			Title:       item.Title,
+			Link:        item.link(),
+			Description: description,
+			PubDate:     pubDate,
+			GUID:        RSSGUID{Value: item.id(), IsPermaLink: "false"},
+		})
+	}
+
+	return &feed, nil
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"html"
-	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -25,6 +28,21 @@ import (
 // header agent-identifier
 const agent = "gator"
 
+// returned by fetchFeed when the feed has not changed since the last fetch
+var errFeedNotModified = errors.New("feed has not been modified")
+
+// delays before fetching a failing feed again
+const (
+	feedBackoffBase = 5 * time.Minute
+	feedBackoffMax  = 24 * time.Hour
+)
+
+// a claimed feed is not claimed again until its fetch has had time to finish
+const (
+	feedFetchTimeout = 2 * time.Minute
+	feedClaimLease   = 10 * time.Minute
+)
+
 // PostgreSQL Error Codes
 const (
 	UniqueViolationErr = pq.ErrorCode("23505")
@@ -46,8 +64,10 @@ type commands struct {
 
 // state... holds the state of the program
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db     *database.Queries
+	conn   *sql.DB
+	cfg    *config.Config
+	output string
 }
 
 // =========
@@ -61,44 +81,90 @@ type RSSFeed struct {
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
 		Items       []RSSItem `xml:"item"`
+
+		// hints for how often the feed should be fetched
+		TTL       string   `xml:"ttl"`
+		SkipHours []string `xml:"skipHours>hour"`
+		SkipDays  []string `xml:"skipDays>day"`
 	} `xml:"channel"`
 }
 
 // One item from a larger RSS feed
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	Author      string  `xml:"author"`
+	GUID        RSSGUID `xml:"guid"`
+
+	// Dublin Core fields, used when pubDate or author are missing
+	DCDate    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	DCCreator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// unique id of an item, which is also its permalink unless isPermaLink is false
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+// cache headers of a feed, the validators are used for conditional requests
+type feedCache struct {
+	ETag         string
+	LastModified string
+	MaxAge       time.Duration
 }
 
 // =============
 // RSS FUNCTIONS
 // =============
 
-// fetches an rss feed from given URL and returns a reference to it
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// fetches an rss feed from given URL and returns a reference to it.
+// the cache validators are sent as a conditional GET, and the ones from
+// the response are returned. errFeedNotModified is returned for a 304.
+func fetchFeed(ctx context.Context, client *http.Client, feedURL string, cache feedCache) (*RSSFeed, feedCache, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, cache, err
 	}
 
 	req.Header.Set("User-Agent", agent)
-	res, err := http.DefaultClient.Do(req)
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, cache, err
 	}
 	defer res.Body.Close()
 
-	rawFeed, err := io.ReadAll(res.Body)
+	if res.StatusCode == http.StatusNotModified {
+		cache.MaxAge = cacheControlMaxAge(res.Header.Get("Cache-Control"))
+		return &RSSFeed{}, cache, errFeedNotModified
+	}
+	if res.StatusCode >= 400 {
+		return &RSSFeed{}, cache, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	newCache := feedCache{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		MaxAge:       cacheControlMaxAge(res.Header.Get("Cache-Control")),
+	}
+
+	rawFeed, err := readDocument(res.Body)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, cache, err
 	}
 
-	var feed RSSFeed
-	err = xml.Unmarshal(rawFeed, &feed)
+	feed, err := parseFeed(res.Header.Get("Content-Type"), rawFeed)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, cache, err
 	}
 
 	// removing html artifacts
@@ -112,7 +178,64 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		feed.Channel.Items[i].Description = html.UnescapeString(feed.Channel.Items[i].Description)
 	}
 
-	return &feed, nil
+	return feed, newCache, nil
+}
+
+// decodes a raw feed document into an RSSFeed.
+// other feed formats are detected and converted into the same shape.
+func parseFeed(contentType string, rawFeed []byte) (*RSSFeed, error) {
+	if isJSONFeed(contentType, rawFeed) {
+		return parseJSONFeed(rawFeed)
+	}
+
+	root, err := xmlRootElement(rawFeed)
+	if err != nil {
+		return &RSSFeed{}, err
+	}
+
+	if root.Space == atomNamespace && root.Local == "feed" {
+		return parseAtomFeed(rawFeed)
+	}
+
+	feed := &RSSFeed{}
+	if root.Space == rdfNamespace && root.Local == "RDF" {
+		feed, err = parseRDFFeed(rawFeed)
+	} else {
+		err = xml.Unmarshal(rawFeed, feed)
+	}
+	if err != nil {
+		return &RSSFeed{}, err
+	}
+
+	applyDublinCore(feed)
+	return feed, nil
+}
+
+// returns the name of the first element in an xml document
+func xmlRootElement(rawFeed []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(rawFeed))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("unable to find root element of feed: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+// returns the guid of an item, and whether it is a permalink.
+// RSS guids are permalinks unless they say otherwise, and null when missing.
+func (g RSSGUID) id() (string, sql.NullBool) {
+	value := strings.TrimSpace(g.Value)
+	if value == "" {
+		return "", sql.NullBool{}
+	}
+
+	isPermaLink := !strings.EqualFold(strings.TrimSpace(g.IsPermaLink), "false")
+	return value, sql.NullBool{Bool: isPermaLink, Valid: true}
 }
 
 // =============
@@ -135,31 +258,236 @@ func checkNumArgs(args []string, targetArgNum int) error {
 	return fmt.Errorf("error processing arguments in main.go:checkNumArgs()")
 }
 
-func scrapeFeeds(s *state) error {
-	feedRecord, err := s.db.GetNextFeedToFetch(context.Background())
+// claims the next feed to fetch by marking it as fetched within a transaction.
+// GetNextFeedToFetch skips rows locked by other workers, and the claim leases the
+// feed by moving next_fetch_at past the fetch, so that multiple workers or agg
+// processes never claim the same feed. the lease is replaced once the fetch ends.
+func claimNextFeed(s *state) (database.Feed, error) {
+	tx, err := s.conn.BeginTx(context.Background(), nil)
 	if err != nil {
-		return fmt.Errorf("scraping feeds error fetching feed list from db: %w", err)
+		return database.Feed{}, fmt.Errorf("unable to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// mark it as fetched
+	// only feeds that are due, and not backing off, are claimed
 	now := sql.NullTime{}
 	now.Scan(time.Now())
 
-	err = s.db.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
+	qtx := s.db.WithTx(tx)
+	feedRecord, err := qtx.GetNextFeedToFetch(context.Background(), now)
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	// mark it as fetched, leasing it until the fetch has finished
+	leaseEnd := sql.NullTime{}
+	leaseEnd.Scan(now.Time.Add(feedClaimLease))
+	err = qtx.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
 		ID:            feedRecord.ID,
 		LastFetchedAt: now,
+		NextFetchAt:   leaseEnd,
+	})
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("unable to update feed as fetched: %w", err)
+	}
+
+	return feedRecord, tx.Commit()
+}
+
+// adds a feed, unless its URL is already known, and follows it as the user.
+// both happen within a transaction, so a feed is never added without a follow.
+// returns the feed, and whether it was created and followed by this call.
+func addAndFollowFeed(ctx context.Context, s *state, params database.CreateFeedParams) (database.Feed, bool, bool, error) {
+	params.Url = normalizeURL(params.Url)
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Feed{}, false, false, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.WithTx(tx)
+	created := false
+	feedRecord, err := getFeedByURL(ctx, qtx, params.Url)
+	if err == sql.ErrNoRows {
+		newFeed, err := qtx.CreateFeed(ctx, params)
+		if err != nil {
+			return database.Feed{}, false, false, fmt.Errorf("unable to insert feed: %w", err)
+		}
+
+		feedRecord, err = qtx.GetFeedByID(ctx, newFeed.ID)
+		if err != nil {
+			return database.Feed{}, false, false, fmt.Errorf("unable to fetch new feed: %w", err)
+		}
+		created = true
+	} else if err != nil {
+		return database.Feed{}, false, false, fmt.Errorf("unable to fetch feed by url: %w", err)
+	}
+
+	// a failed insert would abort the transaction, so existing follows are checked first
+	_, err = qtx.GetFeedFollow(ctx, database.GetFeedFollowParams{
+		UserID: params.UserID,
+		FeedID: feedRecord.ID,
+	})
+	if err == nil {
+		return feedRecord, created, false, tx.Commit()
+	} else if err != sql.ErrNoRows {
+		return database.Feed{}, false, false, fmt.Errorf("unable to fetch feed follow: %w", err)
+	}
+
+	_, err = qtx.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: params.CreatedAt,
+		UpdatedAt: params.UpdatedAt,
+		UserID:    params.UserID,
+		FeedID:    feedRecord.ID,
+	})
+	if err != nil {
+		return database.Feed{}, false, false, fmt.Errorf("unable to insert feed follow: %w", err)
+	}
+
+	return feedRecord, created, true, tx.Commit()
+}
+
+func scrapeFeeds(s *state) error {
+	feedRecord, err := claimNextFeed(s)
+	if err == sql.ErrNoRows {
+		log.Printf("There are no feeds available to fetch.\n")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("scraping feeds error claiming next feed: %w", err)
+	}
+
+	// errors from a single feed are recorded against it,
+	// so that one broken feed does not stop aggregation
+	nextFetchAt, err := scrapeFeed(s, feedRecord)
+	if err != nil {
+		log.Printf("Failed to scrape feed '%s': %s\n", feedRecord.Name, err)
+
+		// failing feeds wait longer after every failure
+		failures := int(feedRecord.ConsecutiveFailures) + 1
+		delay := feedBackoff(failures)
+
+		errorMessage := sql.NullString{}
+		errorMessage.Scan(err.Error())
+		now := sql.NullTime{}
+		now.Scan(time.Now())
+		retryAt := sql.NullTime{}
+		retryAt.Scan(now.Time.Add(delay))
+
+		err = s.db.MarkFeedFailed(context.Background(), database.MarkFeedFailedParams{
+			ID:          feedRecord.ID,
+			LastError:   errorMessage,
+			LastErrorAt: now,
+			NextFetchAt: retryAt,
+		})
+		if err != nil {
+			return fmt.Errorf("scraping feeds error recording feed failure: %w", err)
+		}
+
+		if failures < s.cfg.FeedFailureLimit() {
+			log.Printf("Feed '%s' has failed %d time(s), retrying in %s.\n", feedRecord.Name, failures, delay.String())
+			return nil
+		}
+
+		err = s.db.DisableFeed(context.Background(), database.DisableFeedParams{
+			ID:        feedRecord.ID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("scraping feeds error disabling feed: %w", err)
+		}
+
+		log.Printf("Feed '%s' has failed %d times and was disabled.\n", feedRecord.Name, failures)
+		return nil
+	}
+
+	log.Printf("Feed '%s' is due again at %s.\n", feedRecord.Name, nextFetchAt.Format(time.DateTime))
+	err = s.db.MarkFeedSucceeded(context.Background(), database.MarkFeedSucceededParams{
+		ID:          feedRecord.ID,
+		NextFetchAt: sql.NullTime{Time: nextFetchAt, Valid: true},
 	})
 	if err != nil {
-		return fmt.Errorf("scraping feeds error updating feed as fetched: %w", err)
+		return fmt.Errorf("scraping feeds error resetting feed failures: %w", err)
+	}
+
+	return nil
+}
+
+// returns how long to wait before fetching a feed again,
+// doubling with every consecutive failure up to a maximum.
+func feedBackoff(failures int) time.Duration {
+	delay := feedBackoffBase
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= feedBackoffMax {
+			return feedBackoffMax
+		}
 	}
 
+	return delay
+}
+
+// fetches a single feed and saves its items as posts.
+// returns when the feed should be fetched next.
+func scrapeFeed(s *state, feedRecord database.Feed) (time.Time, error) {
 	log.Printf("Fetching '%s' feed at '%s'.\n", feedRecord.Name, feedRecord.Url)
-	RSSItems, err := fetchFeed(context.Background(), feedRecord.Url)
+	cache := feedCache{
+		ETag:         feedRecord.Etag.String,
+		LastModified: feedRecord.LastModified.String,
+	}
+	interval := time.Duration(feedRecord.FetchInterval.Int32) * time.Second
+
+	// feeds added through the API may only reach public addresses, on every fetch
+	client := feedClient
+	if feedRecord.PublicOnly {
+		client = publicFeedClient
+	}
+
+	// the fetch must end before the claim on the feed expires
+	ctx, cancel := context.WithTimeout(context.Background(), feedFetchTimeout)
+	defer cancel()
+	RSSItems, newCache, err := fetchFeed(ctx, client, feedRecord.Url, cache)
+	if errors.Is(err, errFeedNotModified) {
+		log.Printf("Feed '%s' has not changed since the last fetch.\n", feedRecord.Name)
+		return nextFetchTime(time.Now(), interval, savedScheduleHints(feedRecord), newCache), nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	err = s.db.SetFeedCacheHeaders(context.Background(), database.SetFeedCacheHeadersParams{
+		ID:           feedRecord.ID,
+		Etag:         sql.NullString{String: newCache.ETag, Valid: newCache.ETag != ""},
+		LastModified: sql.NullString{String: newCache.LastModified, Valid: newCache.LastModified != ""},
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error saving cache headers: %w", err)
+	}
+
+	// the hints are kept for scheduling the feed when it is not modified
+	err = s.db.SetFeedScheduleHints(context.Background(), scheduleHints(feedRecord.ID, RSSItems))
 	if err != nil {
-		return fmt.Errorf("scraping feeds error fetching feeds: %w", err)
+		return time.Time{}, fmt.Errorf("error saving schedule hints: %w", err)
 	}
 
+	var created, updated int
 	for _, item := range RSSItems.Channel.Items {
+		// items are identified by their guid within the feed, or by their normalized link
+		guid, guidIsPermalink := item.GUID.id()
+		rawLink := strings.TrimSpace(item.Link)
+		link := normalizePostURL(rawLink)
+		if link == "" && guidIsPermalink.Bool {
+			rawLink = guid
+			link = normalizePostURL(guid)
+		}
+		if guid == "" {
+			guid = link
+		}
+		if guid == "" {
+			log.Printf("Skipping an item without a guid or link.\n")
+			continue
+		}
+
 		title := item.Title
 		if title == "" {
 			title = "[NO TITLE]"
@@ -171,42 +499,71 @@ func scrapeFeeds(s *state) error {
 			log.Printf("post description to NullString error: %s\n", err)
 		}
 
-		publishedTime, err := timeDecode(item.PubDate)
-		if err != nil {
-			log.Fatalf("Error decoding time format provided: %s", err)
-		}
+		// posts with an unknown date are still saved, without a published time
 		publishedAt := sql.NullTime{}
-		err = publishedAt.Scan(publishedTime)
+		publishedTime, err := timeDecode(item.PubDate)
 		if err != nil {
-			log.Printf("post publishedAt to NullTime error: %s\n", err)
+			log.Printf("Error decoding time format provided: %s\n", err)
+		} else {
+			err = publishedAt.Scan(publishedTime)
+			if err != nil {
+				log.Printf("post publishedAt to NullTime error: %s\n", err)
+			}
 		}
 
-		log.Printf("saving post '%s' to database\n", title)
+		author := sql.NullString{}
+		if item.Author != "" {
+			author.Scan(item.Author)
+		}
 
-		// save the item to the database
-		_, err = s.db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-			Title:       title,
-			Url:         item.Link,
-			Description: description,
-			PublishedAt: publishedAt,
-			FeedID:      feedRecord.ID,
-		})
-		if pqErr, ok := err.(*pq.Error); ok {
-			if pqErr.Code == UniqueViolationErr {
-				log.Printf("Post was already added based on its URL.\n")
+		// posts saved before guids and normalized links were stored are identified
+		// by their link as it was given, so they take on the guid of the item
+		// instead of being saved again
+		if rawLink != "" && guid != rawLink {
+			_, err = s.db.SetPostGUIDByURL(context.Background(), database.SetPostGUIDByURLParams{
+				Guid:            guid,
+				GuidIsPermalink: guidIsPermalink,
+				FeedID:          feedRecord.ID,
+				Url:             rawLink,
+			})
+			if err != nil {
+				log.Printf("error matching post '%s' by its link: %s\n", title, err)
 				continue
 			}
+		}
 
+		// save the item to the database, updating it when it has changed
+		upsertedPost, err := s.db.UpsertPost(context.Background(), database.UpsertPostParams{
+			ID:              uuid.New(),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+			Title:           title,
+			Url:             link,
+			Description:     description,
+			PublishedAt:     publishedAt,
+			FeedID:          feedRecord.ID,
+			Author:          author,
+			Guid:            guid,
+			GuidIsPermalink: guidIsPermalink,
+		})
+		if err == sql.ErrNoRows {
+			// the post is known and has not changed
+			continue
 		} else if err != nil {
-			log.Printf("error inserting to posts table: %s\n", err)
+			log.Printf("error saving post '%s' to database: %s\n", title, err)
+			continue
 		}
 
+		if upsertedPost.Inserted {
+			created++
+		} else {
+			updated++
+		}
 	}
 
-	return nil
+	log.Printf("Feed '%s' had %d new and %d updated posts.\n", feedRecord.Name, created, updated)
+
+	return nextFetchTime(time.Now(), interval, RSSItems, newCache), nil
 }
 
 // time decoding function to try multiple different formats.
@@ -216,6 +573,11 @@ func timeDecode(str string) (time.Time, error) {
 	if err == nil {
 		return val, nil
 	}
+	// RFC3339 format, used by Atom feeds
+	val, err = time.Parse(time.RFC3339, str)
+	if err == nil {
+		return val, nil
+	}
 	// UnixDate format
 	val, err = time.Parse(time.UnixDate, str)
 	if err == nil {
@@ -236,341 +598,1769 @@ func timeDecode(str string) (time.Time, error) {
 	if err == nil {
 		return val, nil
 	}
+	// DateOnly format, allowed by Dublin Core dates
+	val, err = time.Parse(time.DateOnly, str)
+	if err == nil {
+		return val, nil
+	}
 
 	return time.Time{}, fmt.Errorf("unknown time format: %s", str)
 }
 
-// ==========
-// MIDDLEWARE
-// ==========
+// looks up the feed by its URL, and checks that the user added it
+func getOwnedFeed(s *state, URL string, user database.User) (database.Feed, error) {
+	feedRecord, err := getFeedByURL(context.Background(), s.db, URL)
+	if err == sql.ErrNoRows {
+		fmt.Printf("Unable to find the feed by URL.\n")
+		os.Exit(1)
+	} else if err != nil {
+		return database.Feed{}, fmt.Errorf("unable to fetch feed record: %w", err)
+	}
 
-// Allows for all handlers that require a logged in user to to accept them as an argument.
-func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
-	// first the outter function runs when registering a command
-	// then it will run the inner code when the command is executed
-	// and the original function will be called finally with the enriched info
-	return func(s *state, c command) error {
+	if feedRecord.UserID != user.ID && !user.IsAdmin {
+		fmt.Printf("Only the user who added '%s', or an admin, can change it.\n", feedRecord.Name)
+		os.Exit(1)
+	}
+
+	return feedRecord, nil
+}
+
+// sets the admin role of the user named in the arguments.
+// admins cannot remove their own role, so that one admin always remains.
+func setAdmin(s *state, c command, user database.User, isAdmin bool) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	username := strings.ToLower(c.arguments[0])
+	userRecord, err := s.db.GetUserByName(context.Background(), username)
+	if err == sql.ErrNoRows {
+		fmt.Printf("User '%s' does not exists.\n", username)
+		os.Exit(1)
+	} else if err != nil {
+		return fmt.Errorf("unable to fetch user by name: %w", err)
+	}
+
+	if userRecord.ID == user.ID && !isAdmin {
+		fmt.Println("You cannot remove your own admin role.")
+		os.Exit(1)
+	}
+
+	err = s.db.SetUserAdmin(context.Background(), database.SetUserAdminParams{
+		ID:        userRecord.ID,
+		IsAdmin:   isAdmin,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set admin role: %w", err)
+	}
+
+	if isAdmin {
+		fmt.Printf("User '%s' is now an admin.\n", username)
+	} else {
+		fmt.Printf("User '%s' is no longer an admin.\n", username)
+	}
+	return nil
+}
+
+// asks to confirm a destructive action, unless it was confirmed with --yes.
+// without a terminal to ask, the action is refused.
+func confirmAction(prompt string, yes bool) bool {
+	if yes {
+		return true
+	}
+
+	if !isInteractive() {
+		fmt.Println("Refusing to continue without confirmation, use --yes to confirm.")
+		return false
+	}
+
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// looks up one of the users folders by name
+func getFolder(s *state, name string, user database.User) (database.Folder, error) {
+	folderRecord, err := s.db.GetFolderByName(context.Background(), database.GetFolderByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err == sql.ErrNoRows {
+		fmt.Printf("Unable to find the folder '%s'.\n", name)
+		fmt.Println("You may need to create the folder first, with 'addfolder'.")
+		os.Exit(1)
+	} else if err != nil {
+		return database.Folder{}, fmt.Errorf("unable to fetch folder record: %w", err)
+	}
+
+	return folderRecord, nil
+}
+
+// moves a followed feed into a folder, or out of any folder when the folder is invalid
+func setFeedFolder(s *state, URL string, folderID uuid.NullUUID, user database.User) (database.Feed, error) {
+	feedRecord, err := getFeedByURL(context.Background(), s.db, URL)
+	if err == sql.ErrNoRows {
+		fmt.Printf("Unable to find the feed by URL.\n")
+		os.Exit(1)
+	} else if err != nil {
+		return database.Feed{}, fmt.Errorf("unable to fetch feed record: %w", err)
+	}
+
+	rows, err := s.db.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+		UserID:    user.ID,
+		FeedID:    feedRecord.ID,
+		FolderID:  folderID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("unable to set folder of feed: %w", err)
+	}
+	if rows == 0 {
+		fmt.Printf("You are not following '%s'.\n", feedRecord.Name)
+		os.Exit(1)
+	}
+
+	return feedRecord, nil
+}
 
-		username := s.cfg.CurrentUsername
-		userRecord, err := s.db.GetUserByName(context.Background(), username)
+// looks up a post by the given ID string.
+// only posts from the feeds the user follows, or that they starred, are found.
+func getPost(s *state, postIDString string, user database.User) (database.GetPostForUserRow, error) {
+	postID, err := uuid.Parse(postIDString)
+	if err != nil {
+		return database.GetPostForUserRow{}, fmt.Errorf("invalid post ID '%s': %w", postIDString, err)
+	}
+
+	postRecord, err := s.db.GetPostForUser(context.Background(), database.GetPostForUserParams{
+		ID:     postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		fmt.Printf("Unable to find the post by ID in the feeds you follow.\n")
+		os.Exit(1)
+	} else if err != nil {
+		return database.GetPostForUserRow{}, fmt.Errorf("unable to fetch post record: %w", err)
+	}
+
+	return postRecord, nil
+}
+
+// marks posts as read or unread for the user, and returns how many changed.
+// either a single post ID, every followed post with --all,
+// or every post of one feed with --feed <URL> is marked.
+func markPosts(s *state, c command, user database.User, read bool) (int64, error) {
+	flags := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	all := flags.Bool("all", false, "mark every post from the feeds you follow")
+	feedURL := flags.String("feed", "", "mark every post from the feed with this URL")
+	if err := flags.Parse(c.arguments); err != nil {
+		return 0, fmt.Errorf("unable to parse arguments: %w", err)
+	}
+
+	now := time.Now()
+	if *all {
+		if read {
+			return s.db.MarkAllPostsRead(context.Background(), database.MarkAllPostsReadParams{
+				ReadAt: now,
+				UserID: user.ID,
+			})
+		}
+		return s.db.MarkAllPostsUnread(context.Background(), database.MarkAllPostsUnreadParams{
+			UserID:    user.ID,
+			UpdatedAt: now,
+		})
+	}
 
+	if *feedURL != "" {
+		feedRecord, err := getFeedByURL(context.Background(), s.db, *feedURL)
 		if err == sql.ErrNoRows {
-			log.Println("There does not appear to be any registered users.")
-			fmt.Println("Please ensure that you are registered and logged in.")
+			fmt.Printf("Unable to find the feed by URL.\n")
 			os.Exit(1)
 		} else if err != nil {
-			log.Println("Unknown error fetching user from database.")
-			return fmt.Errorf("middlewareLoggedIn error fetching user by name: %w", err)
+			return 0, fmt.Errorf("unable to fetch feed record: %w", err)
 		}
 
-		return handler(s, c, userRecord)
+		if read {
+			return s.db.MarkFeedPostsRead(context.Background(), database.MarkFeedPostsReadParams{
+				ReadAt: now,
+				UserID: user.ID,
+				FeedID: feedRecord.ID,
+			})
+		}
+		return s.db.MarkFeedPostsUnread(context.Background(), database.MarkFeedPostsUnreadParams{
+			UserID:    user.ID,
+			UpdatedAt: now,
+			FeedID:    feedRecord.ID,
+		})
 	}
-}
 
-// ================
-// COMMAND HANDLERS
-// ================
+	if err := checkNumArgs(flags.Args(), 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	postRecord, err := getPost(s, flags.Arg(0), user)
+	if err != nil {
+		return 0, err
+	}
+
+	if read {
+		return s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			PostID:    postRecord.ID,
+			ReadAt:    sql.NullTime{Time: now, Valid: true},
+		})
+	}
+	return s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+		UserID:    user.ID,
+		PostID:    postRecord.ID,
+		UpdatedAt: now,
+	})
+}
+
+// looks up a feed by its URL, falling back to its name
+func getFeedByURLOrName(s *state, feed string) (database.Feed, error) {
+	feedRecord, err := getFeedByURL(context.Background(), s.db, feed)
+	if err == sql.ErrNoRows {
+		feedRecord, err = s.db.GetFeedByName(context.Background(), feed)
+	}
+
+	if err == sql.ErrNoRows {
+		fmt.Printf("Unable to find a feed with the URL or name '%s'.\n", feed)
+		os.Exit(1)
+	} else if err != nil {
+		return database.Feed{}, fmt.Errorf("unable to fetch feed record: %w", err)
+	}
+
+	return feedRecord, nil
+}
+
+// parses a date flag given as 2006-01-02 or RFC3339, an empty flag is null.
+// with endOfDay, a date without a time refers to the end of that day.
+func parseDateFlag(str string, endOfDay bool) (sql.NullTime, error) {
+	if str == "" {
+		return sql.NullTime{}, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, str)
+	if err == nil {
+		return sql.NullTime{Time: date, Valid: true}, nil
+	}
+
+	date, err = time.Parse(time.DateOnly, str)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("unknown date format '%s', use 2006-01-02", str)
+	}
+	if endOfDay {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return sql.NullTime{Time: date, Valid: true}, nil
+}
+
+// parses a duration string into the seconds stored for a feeds interval.
+// "none" removes the interval, so the feed is fetched on every agg tick.
+func parseFetchInterval(str string) (sql.NullInt32, error) {
+	if str == "none" {
+		return sql.NullInt32{}, nil
+	}
+
+	interval, err := time.ParseDuration(str)
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+
+	seconds := interval.Seconds()
+	if seconds < 1 || seconds > math.MaxInt32 {
+		return sql.NullInt32{}, fmt.Errorf("interval must be between 1s and %d seconds, got %s", math.MaxInt32, str)
+	}
+
+	return sql.NullInt32{Int32: int32(seconds), Valid: true}, nil
+}
+
+// ==========
+// MIDDLEWARE
+// ==========
+
+// Allows for all handlers that require a logged in user to to accept them as an argument.
+func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	// first the outter function runs when registering a command
+	// then it will run the inner code when the command is executed
+	// and the original function will be called finally with the enriched info
+	return func(s *state, c command) error {
+
+		userRecord, err := sessionUser(context.Background(), s, s.cfg.SessionToken)
+
+		if err == errInvalidSession {
+			log.Println("There is no valid session in the config file.")
+			fmt.Println("Please ensure that you are registered and logged in.")
+			os.Exit(1)
+		} else if err != nil {
+			log.Println("Unknown error fetching user from database.")
+			return fmt.Errorf("middlewareLoggedIn error fetching user by session: %w", err)
+		}
+
+		return handler(s, c, userRecord)
+	}
+}
+
+// Allows destructive commands to be run by admins only.
+// the handler receives the admin user, the same as with middlewareLoggedIn.
+func middlewareAdmin(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return middlewareLoggedIn(func(s *state, c command, user database.User) error {
+		if !user.IsAdmin {
+			fmt.Printf("The '%s' command can only be run by an admin.\n", c.name)
+			os.Exit(1)
+		}
+
+		return handler(s, c, user)
+	})
+}
+
+// ================
+// COMMAND HANDLERS
+// ================
+
+// list of valid command handlers
+var validCommands map[string]string = map[string]string{
+	"addfeed":       "Adds a new feed and follows it. Requires a URL, or a Name & URL.\n   Websites are searched for their feed, the name defaults to its title.\n   Optionally provide an interval between fetches after the Name & URL, e.g. 1h.",
+	"addfolder":     "Creates a folder for organizing the feeds you follow. Requires a Name.",
+	"agg":           "Begins aggregation of feeds.\n   Provide an time interval to wait between each feed.\n   e.g. 30m, 1h, etc.\n   Optionally provide a number of workers fetching at once.",
+	"browse":        "Browse the unread posts from the feeds you follow.\n   Provide an int as a limit of posts.\n   e.g. 1, 5, 20, etc.\n   Flags: --all, --unread=false, --feed <URL|name>, --folder <name>, --since <date>,\n   --until <date>, --offset <n>, --limit <n>, --sort newest|oldest",
+	"categorize":    "Moves a feed you follow into a folder. Requires a URL & folder Name.",
+	"demote":        "Removes the admin role from a user. Admin only. Requires a Name.",
+	"disablefeed":   "Stops fetching a feed you added, admins can stop any feed. Requires a URL.",
+	"enablefeed":    "Resumes fetching a disabled feed you added. Requires a URL.",
+	"export":        "Exports the feeds you follow as OPML.\n   Optionally provide --output <file> and --owned to include feeds you added.",
+	"feeds":         "Shows a list of all feeds.",
+	"feedstatus":    "Shows a list of feeds that are failing to be fetched.",
+	"feedtoken":     "Creates a read-only token for subscribing to your published feed.",
+	"folders":       "Shows a list of your folders.",
+	"follow":        "Follow a feed by its URL.",
+	"following":     "Shows a list of all feeds the current user is following.",
+	"help":          "Shows available commands.",
+	"import":        "Imports and follows the feeds of an OPML file. Requires a file path.",
+	"login":         "Logs into a user. Requires a Name, and prompts for the password.",
+	"logout":        "Logs out of the current user.",
+	"markread":      "Marks posts as read. Requires a post ID, --all or --feed <URL>.",
+	"markunread":    "Marks posts as unread. Requires a post ID, --all or --feed <URL>.",
+	"passwd":        "Changes the password of the current user.",
+	"promote":       "Gives a user the admin role. Admin only. Requires a Name.",
+	"publish":       "Publishes the posts from the feeds you follow as an Atom feed.\n   Optionally provide --output <file>, --limit <n> and --unread.",
+	"register":      "Registers a new user. Requires a Name, and prompts for a password.",
+	"removefeed":    "Removes a feed you added, with its posts and follows. Requires a URL.\n   Asks for confirmation, unless --yes is provided.",
+	"removefolder":  "Removes a folder, its feeds stay followed. Requires a Name.",
+	"renamefeed":    "Renames a feed you added. Requires a URL & Name.",
+	"renamefolder":  "Renames a folder. Requires a Name & new Name.",
+	"reset":         "Reset the 'users' and the 'feeds' table. Admin only.\n   Asks for confirmation, unless --yes is provided.",
+	"search":        "Searches the posts from the feeds you follow. Requires a query.\n   Optionally provide --limit <n> before the query.",
+	"serve":         "Serves the JSON API over HTTP.\n   Optionally provide --addr <address>, defaults to :8080.",
+	"setinterval":   "Sets how often a feed you added is fetched.\n   Requires a URL and an interval, e.g. 1h, or 'none'.",
+	"setpassword":   "Sets the password of another user. Admin only.\n   Requires a username, and prompts for the new password.",
+	"star":          "Stars a post to save it for later. Requires a post ID.",
+	"starred":       "Shows a list of the posts you have starred.",
+	"uncategorize":  "Moves a feed you follow out of its folder. Requires a URL.",
+	"unfollow":      "Unfollow a feed by its URL.",
+	"unstar":        "Removes the star from a post. Requires a post ID.",
+	"updatefeedurl": "Changes the URL of a feed you added, keeping its posts. Requires a URL & new URL.",
+	"users":         "Shows a list of all registered users.",
+}
+
+// add feed command
+func handlerAddFeed(s *state, c command, user database.User) error {
+	numArgs := len(c.arguments)
+	if numArgs < 1 || numArgs > 3 {
+		return fmt.Errorf("handlerAddFeed was passed wrong number of arguments, expected 1 to 3, got %d", numArgs)
+	}
+
+	// the name is optional when only a URL is given
+	userID := user.ID
+	name := ""
+	URL := c.arguments[0]
+	if numArgs >= 2 {
+		name = c.arguments[0]
+		URL = c.arguments[1]
+	}
+
+	// optional interval between fetches of this feed
+	fetchInterval := sql.NullInt32{}
+	if numArgs == 3 {
+		var err error
+		fetchInterval, err = parseFetchInterval(c.arguments[2])
+		if err != nil {
+			return fmt.Errorf("handlerAddFeed error parsing interval: %w", err)
+		}
+	}
+
+	// the URL may be a website, so unknown feeds are discovered and checked first
+	_, err := getFeedByURL(context.Background(), s.db, URL)
+	if err == sql.ErrNoRows {
+		ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+		defer cancel()
+		discovered, feed, err := resolveFeed(ctx, feedClient, URL, chooseFeed)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if discovered.URL != URL {
+			fmt.Printf("Found the feed at '%s'.\n", discovered.URL)
+		}
+		URL = discovered.URL
+
+		if name == "" {
+			name = feedName(feed, discovered)
+		}
+	} else if err != nil {
+		return fmt.Errorf("handlerAddFeed error fetching feed by url: %w", err)
+	}
+
+	feedRecord, created, followed, err := addAndFollowFeed(context.Background(), s, database.CreateFeedParams{
+		ID:            uuid.New(),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		Name:          name,
+		Url:           URL,
+		UserID:        userID,
+		FetchInterval: fetchInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("handlerAddFeed error: %w", err)
+	}
+
+	// the name and interval only apply to new feeds
+	if !created {
+		fmt.Printf("Feed has already been added as '%s'.\n", feedRecord.Name)
+	}
+	if !followed {
+		fmt.Printf("%s is already following %s\n", user.Name, feedRecord.Name)
+		return nil
+	}
+
+	fmt.Printf("%s is now following %s\n", user.Name, feedRecord.Name)
+	return nil
+}
+
+// creates a folder for organizing the feeds you follow
+func handlerAddFolder(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	name := strings.TrimSpace(c.arguments[0])
+	if name == "" {
+		fmt.Println("The name of the folder cannot be empty.")
+		os.Exit(1)
+	}
+
+	_, err := s.db.CreateFolder(context.Background(), database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+	})
+	if isUniqueViolation(err) {
+		fmt.Printf("The folder '%s' already exists.\n", name)
+		os.Exit(1)
+	} else if err != nil {
+		return fmt.Errorf("handlerAddFolder error creating folder: %w", err)
+	}
+
+	fmt.Printf("Created the folder '%s'.\n", name)
+	return nil
+}
+
+// Command to run in another terminal, will fetch the feeds in the background.
+// This function needs to be explicitly terminated.
+func handlerAgg(s *state, c command) error {
+	numArgs := len(c.arguments)
+	if numArgs < 1 || numArgs > 2 {
+		fmt.Println("agg requires a duration, and optionally a number of workers.")
+		os.Exit(1)
+	}
+
+	// takes a duration string as an argument
+	// e.g. 1h, 1m, 30m, etc.
+	durationString := c.arguments[0]
+	duration, err := time.ParseDuration(durationString)
+	if err != nil {
+		return fmt.Errorf("handler agg unable to parse duration string: %w", err)
+	}
+
+	// number of workers fetching feeds at the same time
+	workers := 1
+	if numArgs == 2 {
+		workers, err = strconv.Atoi(c.arguments[1])
+		if err != nil || workers < 1 {
+			return fmt.Errorf("handlerAgg number of workers must be a positive number, got '%s'", c.arguments[1])
+		}
+	}
+	log.Printf("Collecting feeds every %s with %d worker(s)\n", duration.String(), workers)
+
+	// each worker claims its own feed on every tick
+	errs := make(chan error, workers)
+	for i := range workers {
+		go aggWorker(s, i+1, duration, errs)
+	}
+
+	// the first worker to fail stops aggregation
+	return <-errs
+}
+
+// scrapes one feed on every tick, until scraping returns an error
+func aggWorker(s *state, workerID int, duration time.Duration, errs chan<- error) {
+	// sets up a ticker to execute the scraping
+	ticker := time.NewTicker(duration)
+	for ; ; <-ticker.C {
+
+		err := scrapeFeeds(s)
+		if err != nil {
+			errs <- fmt.Errorf("agg worker %d: %w", workerID, err)
+			return
+		}
+
+		log.Printf("Worker %d waiting %s to fetch next feed.\n", workerID, duration.String())
+	}
+}
+
+// browse the downloaded posts.
+// only unread posts are shown, unless --unread=false or --all is provided.
+// flags must come before the optional limit, e.g. "browse --sort oldest 10".
+func handlerBrowse(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("browse", flag.ContinueOnError)
+	unread := flags.Bool("unread", true, "only show posts that have not been read")
+	all := flags.Bool("all", false, "include posts that have been read, same as --unread=false")
+	feed := flags.String("feed", "", "only show posts from the feed with this URL or name")
+	folder := flags.String("folder", "", "only show posts from the feeds in this folder")
+	since := flags.String("since", "", "only show posts published at or after this date")
+	until := flags.String("until", "", "only show posts published before the end of this date")
+	offset := flags.Int("offset", 0, "number of posts to skip, for paging")
+	limit := flags.Int("limit", 10, "number of posts to show")
+	sort := flags.String("sort", "newest", "order of posts, newest or oldest")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerBrowse error parsing arguments: %w", err)
+	}
+
+	// the limit can also be given as the only argument
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		var err error
+		*limit, err = strconv.Atoi(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("handlerBrowse error parsing limit: %w", err)
+		}
+	default:
+		fmt.Println("browse accepts only a limit after its flags.")
+		os.Exit(1)
+	}
+
+	if *limit < 1 || *offset < 0 {
+		return fmt.Errorf("handlerBrowse limit must be positive and offset must not be negative")
+	}
+	if *sort != "newest" && *sort != "oldest" {
+		return fmt.Errorf("handlerBrowse sort must be 'newest' or 'oldest', got '%s'", *sort)
+	}
+
+	params := database.BrowsePostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: *all || !*unread,
+		OldestFirst: *sort == "oldest",
+		PostLimit:   int64(*limit),
+		PostOffset:  int64(*offset),
+	}
+
+	if *feed != "" {
+		feedRecord, err := getFeedByURLOrName(s, *feed)
+		if err != nil {
+			return fmt.Errorf("handlerBrowse error: %w", err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedRecord.ID, Valid: true}
+	}
+
+	if *folder != "" {
+		folderRecord, err := getFolder(s, *folder, user)
+		if err != nil {
+			return fmt.Errorf("handlerBrowse error: %w", err)
+		}
+		params.FolderID = uuid.NullUUID{UUID: folderRecord.ID, Valid: true}
+	}
+
+	var err error
+	params.Since, err = parseDateFlag(*since, false)
+	if err != nil {
+		return fmt.Errorf("handlerBrowse error parsing --since: %w", err)
+	}
+	params.Until, err = parseDateFlag(*until, true)
+	if err != nil {
+		return fmt.Errorf("handlerBrowse error parsing --until: %w", err)
+	}
+
+	posts, err := s.db.BrowsePostsForUser(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("handlerBrowse unable to fetch posts from database: %w", err)
+	}
+	log.Printf("Fetched %d posts from database\n", len(posts))
+
+	if s.output != "" {
+		var postOutputs []postOutput
+		for _, post := range posts {
+			postOutputs = append(postOutputs, postOutput{
+				ID:          post.ID.String(),
+				Title:       post.Title,
+				URL:         post.Url,
+				Description: post.Description.String,
+				Author:      post.Author.String,
+				FeedID:      post.FeedID.String(),
+				FeedName:    post.FeedName,
+				PublishedAt: optionalTime(post.PublishedAt.Time, post.PublishedAt.Valid),
+				Read:        post.Read,
+				Starred:     post.Starred,
+			})
+		}
+
+		header := []string{"id", "title", "url", "description", "author",
+			"feed_id", "feed_name", "published_at", "read", "starred"}
+		return writeListing(s.output, postOutputs, header, func(p postOutput) []string {
+			description := p.Description
+			if s.output == outputTable {
+				description = singleLine(description)
+			}
+			return []string{p.ID, p.Title, p.URL, description, p.Author,
+				p.FeedID, p.FeedName, formatOptionalTime(p.PublishedAt),
+				strconv.FormatBool(p.Read), strconv.FormatBool(p.Starred)}
+		})
+	}
+
+	fmt.Printf("Showing %d posts, starting at %d:\n", len(posts), *offset+1)
+	for _, post := range posts {
+		marker := ""
+		if post.Starred {
+			marker += "★ "
+		}
+		if post.Read {
+			marker += "(read) "
+		}
+
+		fmt.Printf(" * %s%s * \n", marker, post.Title)
+		fmt.Printf(" * ID: %s\n", post.ID)
+		fmt.Printf(" * Feed: %s\n", post.FeedName)
+		fmt.Printf(" * Published at: %s\n", post.PublishedAt.Time.String())
+		fmt.Printf("%s\n\n", post.Description.String)
+	}
+
+	if len(posts) == *limit {
+		fmt.Printf("Use --offset %d to see the next posts.\n", *offset+*limit)
+	}
+
+	return nil
+}
+
+// moves a feed you follow into one of your folders
+func handlerCategorize(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 2); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	folderRecord, err := getFolder(s, c.arguments[1], user)
+	if err != nil {
+		return fmt.Errorf("handlerCategorize error: %w", err)
+	}
+
+	feedRecord, err := setFeedFolder(s, c.arguments[0], uuid.NullUUID{UUID: folderRecord.ID, Valid: true}, user)
+	if err != nil {
+		return fmt.Errorf("handlerCategorize error: %w", err)
+	}
+
+	fmt.Printf("Moved '%s' into the folder '%s'.\n", feedRecord.Name, folderRecord.Name)
+	return nil
+}
+
+// removes the admin role from a user
+func handlerDemote(s *state, c command, user database.User) error {
+	return setAdmin(s, c, user, false)
+}
+
+// stops a feed from being fetched by agg
+func handlerDisableFeed(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedRecord, err := getOwnedFeed(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerDisableFeed error: %w", err)
+	}
+
+	err = s.db.DisableFeed(context.Background(), database.DisableFeedParams{
+		ID:        feedRecord.ID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerDisableFeed error disabling feed: %w", err)
+	}
+
+	fmt.Printf("Disabled '%s' successfully.\n", feedRecord.Name)
+	return nil
+}
+
+// allows a disabled feed to be fetched by agg again
+// also resets its failures so it is fetched on the next tick
+func handlerEnableFeed(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedRecord, err := getOwnedFeed(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerEnableFeed error: %w", err)
+	}
+
+	err = s.db.EnableFeed(context.Background(), database.EnableFeedParams{
+		ID:        feedRecord.ID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerEnableFeed error enabling feed: %w", err)
+	}
+
+	fmt.Printf("Enabled '%s' successfully.\n", feedRecord.Name)
+	return nil
+}
+
+// exports the feeds the current user follows as an OPML document.
+// feeds added by the user that they do not follow can be included.
+func handlerExport(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "file to write the OPML document to, defaults to stdout")
+	owned := flags.Bool("owned", false, "include feeds you added but do not follow")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerExport error parsing arguments: %w", err)
+	}
+	if err := checkNumArgs(flags.Args(), 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedFollowRecords, err := s.db.GetFeedFollowForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("handlerExport error fetching users feeds by user id: %w", err)
+	}
+
+	opml := OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       fmt.Sprintf("%s's gator subscriptions", user.Name),
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+
+	exported := make(map[uuid.UUID]bool)
+	for _, feedFollowRecord := range feedFollowRecords {
+		opml.Body.Outlines = append(opml.Body.Outlines,
+			newSubscriptionOutline(feedFollowRecord.FeedName, feedFollowRecord.FeedUrl))
+		exported[feedFollowRecord.FeedID] = true
+	}
+
+	if *owned {
+		feedRecords, err := s.db.GetFeedsByUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("handlerExport error fetching feeds added by user: %w", err)
+		}
+
+		for _, feedRecord := range feedRecords {
+			if exported[feedRecord.ID] {
+				continue
+			}
+			opml.Body.Outlines = append(opml.Body.Outlines,
+				newSubscriptionOutline(feedRecord.Name, feedRecord.Url))
+		}
+	}
+
+	err = writeOPML(opml, *output)
+	if err != nil {
+		return fmt.Errorf("handlerExport error writing opml: %w", err)
+	}
+
+	if *output != "" {
+		fmt.Printf("Exported %d feeds to '%s'.\n", len(opml.Body.Outlines), *output)
+	}
+	return nil
+}
+
+// prints out a list of feeds in the database
+func handlerFeeds(s *state, c command) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feeds, err := s.db.GetAllFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("handlerFeeds error fetching all feeds: %w", err)
+	}
+
+	if s.output != "" {
+		var feedOutputs []feedOutput
+		for _, feed := range feeds {
+			user, err := s.db.GetUserByID(context.Background(), feed.UserID)
+			if err != nil {
+				return fmt.Errorf("unable to get user by id: %w", err)
+			}
+
+			feedOutputs = append(feedOutputs, feedOutput{
+				ID:            feed.ID.String(),
+				Name:          feed.Name,
+				URL:           feed.Url,
+				AddedBy:       user.Name,
+				CreatedAt:     feed.CreatedAt,
+				LastFetchedAt: optionalTime(feed.LastFetchedAt.Time, feed.LastFetchedAt.Valid),
+			})
+		}
+
+		header := []string{"id", "name", "url", "added_by", "created_at", "last_fetched_at"}
+		return writeListing(s.output, feedOutputs, header, func(f feedOutput) []string {
+			return []string{f.ID, f.Name, f.URL, f.AddedBy,
+				f.CreatedAt.Format(time.RFC3339), formatOptionalTime(f.LastFetchedAt)}
+		})
+	}
+
+	fmt.Printf("Feeds that have been added:\n")
+
+	for i, feed := range feeds {
+		user, err := s.db.GetUserByID(context.Background(), feed.UserID)
+		if err != nil {
+			return fmt.Errorf("unable to get user by id: %w", err)
+		}
+
+		fmt.Printf("Feed #%d:\n", i+1)
+		fmt.Printf(" - Added by: %s\n", user.Name)
+		fmt.Printf(" - Name: %s\n", feed.Name)
+		fmt.Printf(" - URL:  %s\n", feed.Url)
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+// prints out a list of feeds that failed on their latest fetches
+func handlerFeedStatus(s *state, c command) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feeds, err := s.db.GetFailingFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("handlerFeedStatus error fetching failing feeds: %w", err)
+	}
+
+	if s.output != "" {
+		var feedStatusOutputs []feedStatusOutput
+		for _, feed := range feeds {
+			feedStatusOutputs = append(feedStatusOutputs, feedStatusOutput{
+				ID:                  feed.ID.String(),
+				Name:                feed.Name,
+				URL:                 feed.Url,
+				ConsecutiveFailures: feed.ConsecutiveFailures,
+				Disabled:            feed.Disabled,
+				LastError:           feed.LastError.String,
+				LastErrorAt:         optionalTime(feed.LastErrorAt.Time, feed.LastErrorAt.Valid),
+			})
+		}
+
+		header := []string{"id", "name", "url", "consecutive_failures", "disabled", "last_error", "last_error_at"}
+		return writeListing(s.output, feedStatusOutputs, header, func(f feedStatusOutput) []string {
+			lastError := f.LastError
+			if s.output == outputTable {
+				lastError = singleLine(lastError)
+			}
+			return []string{f.ID, f.Name, f.URL, strconv.Itoa(int(f.ConsecutiveFailures)),
+				strconv.FormatBool(f.Disabled), lastError, formatOptionalTime(f.LastErrorAt)}
+		})
+	}
+
+	if len(feeds) == 0 {
+		fmt.Println("All feeds are being fetched successfully.")
+		return nil
+	}
+
+	fmt.Printf("Feeds that are failing:\n")
+	for _, feed := range feeds {
+		fmt.Printf(" * %s\n", feed.Name)
+		fmt.Printf(" - URL:  %s\n", feed.Url)
+		fmt.Printf(" - Consecutive failures: %d\n", feed.ConsecutiveFailures)
+		if feed.Disabled {
+			fmt.Printf(" - Disabled, use 'enablefeed' to fetch it again\n")
+		}
+		fmt.Printf(" - Last error at: %s\n", feed.LastErrorAt.Time.String())
+		fmt.Printf(" - Last error: %s\n", feed.LastError.String)
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+// creates a read-only token for subscribing to your published feed from the API.
+// the token replaces any earlier one, which stops working.
+func handlerFeedToken(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	token, err := createFeedToken(context.Background(), s, user.ID)
+	if err != nil {
+		return fmt.Errorf("handlerFeedToken error: %w", err)
+	}
+
+	fmt.Printf("Feed token: %s\n", token)
+	fmt.Printf("Subscribe to '%s' on the server started by 'serve'.\n", userFeedPath(user, token))
+	fmt.Printf("The token can only read your feed, and creating a new token stops it from working.\n")
+	return nil
+}
+
+// lists your folders, with the number of feeds in each
+func handlerFolders(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	folderRecords, err := s.db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("handlerFolders error fetching folders: %w", err)
+	}
+
+	if s.output != "" {
+		var folderOutputs []folderOutput
+		for _, folderRecord := range folderRecords {
+			folderOutputs = append(folderOutputs, folderOutput{
+				ID:        folderRecord.ID.String(),
+				Name:      folderRecord.Name,
+				FeedCount: folderRecord.FeedCount,
+				CreatedAt: folderRecord.CreatedAt,
+			})
+		}
+
+		header := []string{"id", "name", "feed_count", "created_at"}
+		return writeListing(s.output, folderOutputs, header, func(f folderOutput) []string {
+			return []string{f.ID, f.Name, strconv.FormatInt(f.FeedCount, 10), f.CreatedAt.Format(time.RFC3339)}
+		})
+	}
+
+	if len(folderRecords) == 0 {
+		fmt.Println("You do not have any folders, create one with 'addfolder'.")
+		return nil
+	}
+
+	for _, folderRecord := range folderRecords {
+		fmt.Printf(" - %s (%d feeds)\n", folderRecord.Name, folderRecord.FeedCount)
+	}
+	return nil
+}
+
+// as the current user, follows a feed
+// prints the name of the feed and the current user
+func handlerFollow(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	URL := c.arguments[0]
+	feedRecord, err := getFeedByURL(context.Background(), s.db, URL)
+	if err == sql.ErrNoRows {
+		log.Printf("Unable to find the feed using a URL.\n")
+		fmt.Printf("You may need to add the feed first, with 'addfeed'.\n")
+		os.Exit(1)
+	} else if err != nil {
+		return fmt.Errorf("handlerfollow error fetching feed by url: %w", err)
+	}
+
+	_, err = s.db.CreateFeedFollow(context.Background(),
+		database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feedRecord.ID,
+		})
+	if err != nil {
+		return fmt.Errorf("handlerFollow error creating feed follow record: %w", err)
+	}
+
+	fmt.Printf("User %s is now following %s\n", user.Name, feedRecord.Name)
+	return nil
+}
+
+// prints out a list of all feeds the current user is following
+func handlerFollowing(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedFollowRecords, err := s.db.GetFeedFollowForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("handlerFollowing error fetching users feeds by user id: %w", err)
+	}
+
+	if s.output != "" {
+		var followOutputs []followOutput
+		for _, feedFollowRecord := range feedFollowRecords {
+			followOutputs = append(followOutputs, followOutput{
+				FeedID:     feedFollowRecord.FeedID.String(),
+				FeedName:   feedFollowRecord.FeedName,
+				FeedURL:    feedFollowRecord.FeedUrl,
+				Folder:     feedFollowRecord.FolderName.String,
+				FollowedAt: feedFollowRecord.CreatedAt,
+			})
+		}
+
+		header := []string{"feed_id", "feed_name", "feed_url", "folder", "followed_at"}
+		return writeListing(s.output, followOutputs, header, func(f followOutput) []string {
+			return []string{f.FeedID, f.FeedName, f.FeedURL, f.Folder, f.FollowedAt.Format(time.RFC3339)}
+		})
+	}
+
+	// feeds without a folder are listed first, then each folder in order
+	fmt.Printf("User %s is following these feeds:\n", user.Name)
+	currentFolder := ""
+	for _, feedFollowRecord := range feedFollowRecords {
+		if feedFollowRecord.FolderName.String != currentFolder {
+			currentFolder = feedFollowRecord.FolderName.String
+			fmt.Printf("%s/\n", currentFolder)
+		}
+
+		indent := ""
+		if currentFolder != "" {
+			indent = "  "
+		}
+		fmt.Printf("%s - %s\n", indent, feedFollowRecord.FeedName)
+	}
+	return nil
+}
+
+// prints out valid commands
+func handlerHelp(_ *state, c command) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Available commands:")
+	for cName, cDesc := range validCommands {
+		fmt.Printf(" - %s: %s\n", cName, cDesc)
+	}
+	fmt.Println("")
+
+	return nil
+}
+
+// imports the subscriptions of an OPML file, and follows them as the current user.
+// feeds that have not been added yet are created.
+func handlerImport(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	opml, err := readOPML(c.arguments[0])
+	if err != nil {
+		return fmt.Errorf("handlerImport error reading opml file: %w", err)
+	}
+
+	var created, existing, failed int
+	for _, outline := range subscriptionOutlines(opml.Body.Outlines) {
+		URL := strings.TrimSpace(outline.XMLURL)
+
+		_, isNew, followed, err := addAndFollowFeed(context.Background(), s, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      outline.name(),
+			Url:       URL,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			log.Printf("Unable to add feed '%s': %s\n", URL, err)
+			failed++
+			continue
+		}
+		if !followed {
+			log.Printf("Already following '%s'.\n", URL)
+		}
+
+		if isNew {
+			created++
+		} else {
+			existing++
+		}
+	}
+
+	fmt.Printf("Imported feeds for %s:\n", user.Name)
+	fmt.Printf(" - Created:  %d\n", created)
+	fmt.Printf(" - Existing: %d\n", existing)
+	fmt.Printf(" - Failed:   %d\n", failed)
+	return nil
+}
+
+// logs in a given user
+// sets the given user within the configuration json
+func handlerLogin(s *state, c command) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	username := c.arguments[0]
+	username = strings.ToLower(username)
+
+	// check database for user
+	dbFoundUser, err := s.db.GetUserByName(context.Background(), username)
+	if err == sql.ErrNoRows { // user not in database
+		log.Printf("Unable to find record in database for %s.\n", username)
+		fmt.Printf("User '%s' does not exists.\n", username)
+		os.Exit(1)
+	} else if err != nil {
+		return fmt.Errorf("handlerLogin error fetching user by name: %w", err)
+	}
+
+	// users registered before passwords existed are given one by an admin,
+	// as choosing it at login would let anyone take over the account
+	if !dbFoundUser.HashedPassword.Valid {
+		fmt.Printf("User '%s' does not have a password yet, ask an admin to set one with 'setpassword'.\n", username)
+		os.Exit(1)
+	}
+
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return fmt.Errorf("handlerLogin error reading password: %w", err)
+	}
+
+	if !checkPassword(dbFoundUser, password) {
+		fmt.Println("Incorrect password.")
+		os.Exit(1)
+	}
+
+	token, _, err := createSession(context.Background(), s, dbFoundUser.ID)
+	if err != nil {
+		return fmt.Errorf("handlerLogin error creating session: %w", err)
+	}
+
+	err = s.cfg.SetSession(token)
+	if err != nil {
+		return fmt.Errorf("handlerLogin error setting session in config: %w", err)
+	}
+
+	fmt.Printf("Logged into username:'%v' successfully.\n", username)
+	return nil
+}
+
+// ends the current session, on this machine
+func handlerLogout(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err := s.db.DeleteSession(context.Background(), hashSessionToken(s.cfg.SessionToken))
+	if err != nil {
+		return fmt.Errorf("handlerLogout error deleting session: %w", err)
+	}
+
+	err = s.cfg.SetSession("")
+	if err != nil {
+		return fmt.Errorf("handlerLogout error clearing session in config: %w", err)
+	}
+
+	fmt.Printf("Logged out of username:'%v' successfully.\n", user.Name)
+	return nil
+}
+
+// marks posts as read for the current user
+func handlerMarkRead(s *state, c command, user database.User) error {
+	count, err := markPosts(s, c, user, true)
+	if err != nil {
+		return fmt.Errorf("handlerMarkRead error: %w", err)
+	}
+
+	fmt.Printf("Marked %d post(s) as read.\n", count)
+	return nil
+}
+
+// marks posts as unread for the current user
+func handlerMarkUnread(s *state, c command, user database.User) error {
+	count, err := markPosts(s, c, user, false)
+	if err != nil {
+		return fmt.Errorf("handlerMarkUnread error: %w", err)
+	}
+
+	fmt.Printf("Marked %d post(s) as unread.\n", count)
+	return nil
+}
+
+// changes the password of the current user
+func handlerPasswd(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	password, err := readPassword("Current password: ")
+	if err != nil {
+		return fmt.Errorf("handlerPasswd error reading password: %w", err)
+	}
+	if !checkPassword(user, password) {
+		fmt.Println("Incorrect password.")
+		os.Exit(1)
+	}
+
+	hashedPassword, err := readNewPassword()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:             user.ID,
+		HashedPassword: hashedPassword,
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerPasswd error setting password: %w", err)
+	}
+
+	// only the session on this machine stays logged in
+	ended, err := endOtherSessions(context.Background(), s, user.ID, s.cfg.SessionToken)
+	if err != nil {
+		return fmt.Errorf("handlerPasswd error: %w", err)
+	}
+
+	fmt.Printf("Changed the password of '%s', and logged out %d other session(s).\n", user.Name, ended)
+	return nil
+}
+
+// gives another user the admin role
+func handlerPromote(s *state, c command, user database.User) error {
+	return setAdmin(s, c, user, true)
+}
+
+// publishes the posts from the feeds you follow as a single Atom feed
+func handlerPublish(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("publish", flag.ContinueOnError)
+	output := flags.String("output", "", "file to write the Atom feed to, defaults to stdout")
+	limit := flags.Int("limit", defaultPublishLimit, "number of posts to publish")
+	unread := flags.Bool("unread", false, "only publish posts that have not been read")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerPublish error parsing arguments: %w", err)
+	}
+	if err := checkNumArgs(flags.Args(), 0); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *limit < 1 {
+		return fmt.Errorf("handlerPublish limit must be positive")
+	}
+
+	atomFeed, err := newUserAtomFeed(context.Background(), s, user, database.GetPostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: !*unread,
+		PostLimit:   int64(*limit),
+	}, "")
+	if err != nil {
+		return fmt.Errorf("handlerPublish error building feed: %w", err)
+	}
+
+	err = writeAtomFeed(atomFeed, *output)
+	if err != nil {
+		return fmt.Errorf("handlerPublish error writing feed: %w", err)
+	}
+
+	if *output != "" {
+		fmt.Printf("Published %d posts to '%s'.\n", len(atomFeed.Entries), *output)
+	}
+	return nil
+}
+
+// registers a new user
+func handlerRegister(s *state, c command) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// name processing
+	username := c.arguments[0]
+	username = strings.ToLower(username)
+
+	// check in the DB for existing user
+	dbFoundUser, err := s.db.GetUserByName(context.Background(), username)
+	if dbFoundUser.Name == username {
+		fmt.Printf("User '%s' already exists.\n", username)
+		os.Exit(1)
+	}
+
+	hashedPassword, err := readNewPassword()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// the first user to register becomes the admin
+	userCount, err := s.db.CountUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("handlerRegister error counting users: %w", err)
+	}
+
+	// if username does not exist create a new user in the database
+	dbUser, err := s.db.CreateUser(context.Background(),
+		database.CreateUserParams{
+			ID:             uuid.New(),
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+			Name:           username,
+			HashedPassword: hashedPassword,
+			IsAdmin:        userCount == 0,
+		})
+	if err != nil {
+		fmt.Printf("Error inserting new user: %s\n", err)
+		os.Exit(1)
+	}
+
+	// logs into this new user in the config
+	token, _, err := createSession(context.Background(), s, dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("handlerRegister error creating session: %w", err)
+	}
+
+	err = s.cfg.SetSession(token)
+	if err != nil {
+		return fmt.Errorf("handlerRegister error setting session in config: %w", err)
+	}
+
+	fmt.Printf("New user was created: '%s'\nUser: %+v\n", username, dbUser)
+	return nil
+}
+
+// removes a feed you added, along with its posts and follows
+func handlerRemoveFeed(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("removefeed", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "confirm without being asked")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerRemoveFeed error parsing arguments: %w", err)
+	}
+	if err := checkNumArgs(flags.Args(), 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedRecord, err := getOwnedFeed(s, flags.Arg(0), user)
+	if err != nil {
+		return fmt.Errorf("handlerRemoveFeed error: %w", err)
+	}
+
+	prompt := fmt.Sprintf("This deletes '%s' with all of its posts and follows. Continue?", feedRecord.Name)
+	if !confirmAction(prompt, *yes) {
+		fmt.Println("Removing the feed was cancelled.")
+		os.Exit(1)
+	}
+
+	err = s.db.DeleteFeed(context.Background(), feedRecord.ID)
+	if err != nil {
+		return fmt.Errorf("handlerRemoveFeed error deleting feed: %w", err)
+	}
+
+	fmt.Printf("Removed '%s' successfully.\n", feedRecord.Name)
+	return nil
+}
+
+// deletes one of your folders, the feeds in it stay followed
+func handlerRemoveFolder(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	folderRecord, err := getFolder(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerRemoveFolder error: %w", err)
+	}
+
+	err = s.db.DeleteFolder(context.Background(), folderRecord.ID)
+	if err != nil {
+		return fmt.Errorf("handlerRemoveFolder error deleting folder: %w", err)
+	}
+
+	fmt.Printf("Removed the folder '%s', its feeds are still followed.\n", folderRecord.Name)
+	return nil
+}
+
+// renames a feed you added
+func handlerRenameFeed(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 2); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedRecord, err := getOwnedFeed(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerRenameFeed error: %w", err)
+	}
+
+	name := strings.TrimSpace(c.arguments[1])
+	if name == "" {
+		fmt.Println("The new name of the feed cannot be empty.")
+		os.Exit(1)
+	}
+
+	err = s.db.RenameFeed(context.Background(), database.RenameFeedParams{
+		ID:        feedRecord.ID,
+		Name:      name,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerRenameFeed error renaming feed: %w", err)
+	}
 
-// list of valid command handlers
-var validCommands map[string]string = map[string]string{
-	"addfeed":   "Adds a new feed and follows it. Requires a Name & URL.",
-	"agg":       "Begins aggregation of feeds.\n   Provide an time interval to wait between each feed.\n   e.g. 30m, 1h, etc.",
-	"browse":    "Browse the downloaded posts from the feeds you follow.\n   Provide an int as a limit of posts.\n   e.g. 1, 5, 20, etc.",
-	"feeds":     "Shows a list of all feeds.",
-	"follow":    "Follow a feed by its URL.",
-	"following": "Shows a list of all feeds the current user is following.",
-	"help":      "Shows available commands.",
-	"login":     "Logs into a user. Requires a Name.",
-	"register":  "Registers a new user. Requires a Name.",
-	"reset":     "Reset the 'users' and the 'feeds' table",
-	"unfollow":  "Unfollow a feed by its URL.",
-	"users":     "Shows a list of all registered users.",
+	fmt.Printf("Renamed '%s' to '%s' successfully.\n", feedRecord.Name, name)
+	return nil
 }
 
-// add feed command
-func handlerAddFeed(s *state, c command, user database.User) error {
+// renames one of your folders
+func handlerRenameFolder(s *state, c command, user database.User) error {
 	if err := checkNumArgs(c.arguments, 2); err != nil {
-		return fmt.Errorf("handlerAddFeed was passed wrong number of arguments, expected 2: %w", err)
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	userID := user.ID
-	name := c.arguments[0]
-	URL := c.arguments[1]
+	folderRecord, err := getFolder(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerRenameFolder error: %w", err)
+	}
 
-	newFeed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	name := strings.TrimSpace(c.arguments[1])
+	if name == "" {
+		fmt.Println("The new name of the folder cannot be empty.")
+		os.Exit(1)
+	}
+
+	err = s.db.RenameFolder(context.Background(), database.RenameFolderParams{
+		ID:        folderRecord.ID,
 		Name:      name,
-		Url:       URL,
-		UserID:    userID,
+		UpdatedAt: time.Now(),
 	})
-	if pqErr, ok := err.(*pq.Error); ok {
-		if pqErr.Code == UniqueViolationErr {
-			log.Printf("Feed has already been added.\n")
-			return nil
-		}
+	if isUniqueViolation(err) {
+		fmt.Printf("The folder '%s' already exists.\n", name)
+		os.Exit(1)
 	} else if err != nil {
-		return fmt.Errorf("handlerAddFeed error inserting new feed: %w", err)
+		return fmt.Errorf("handlerRenameFolder error renaming folder: %w", err)
 	}
 
-	feedFollowRecord, err := s.db.CreateFeedFollow(context.Background(),
-		database.CreateFeedFollowParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			UserID:    userID,
-			FeedID:    newFeed.ID,
-		})
-
-	fmt.Printf("%s is now following %s\n", user.Name, feedFollowRecord.FeedName)
+	fmt.Printf("Renamed the folder '%s' to '%s'.\n", folderRecord.Name, name)
 	return nil
 }
 
-// Command to run in another terminal, will fetch the feeds in the background.
-// This function needs to be explicitly terminated.
-func handlerAgg(s *state, c command) error {
-	if err := checkNumArgs(c.arguments, 1); err != nil {
+// resets database by deleting all records on user table
+// this will delete the records in the feeds table as well.
+func handlerReset(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("reset", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "confirm without being asked")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerReset error parsing arguments: %w", err)
+	}
+	if err := checkNumArgs(flags.Args(), 0); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// takes a duration string as an argument
-	// e.g. 1h, 1m, 30m, etc.
-	durationString := c.arguments[0]
-	duration, err := time.ParseDuration(durationString)
+	if !confirmAction("This deletes every user, feed, follow and post. Continue?", *yes) {
+		fmt.Println("Reset was cancelled.")
+		os.Exit(1)
+	}
+
+	err := s.db.ResetUsers(context.Background())
 	if err != nil {
-		return fmt.Errorf("handler agg unable to parse duration string: %w", err)
+		return fmt.Errorf("handlerReset was unable to reset the users table: %w", err)
 	}
-	log.Printf("Collecting feeds every %s\n", duration.String())
 
-	// sets up a ticker to execute the scraping
-	ticker := time.NewTicker(duration)
-	for ; ; <-ticker.C {
+	fmt.Println("Reset 'user' table successfully.")
+	return nil
+}
 
-		err := scrapeFeeds(s)
-		if err != nil {
-			return err
-		}
+// searches the titles and descriptions of posts from the feeds the user follows.
+// results are ranked, with the matching words highlighted in [brackets].
+func handlerSearch(s *state, c command, user database.User) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := flags.Int("limit", 10, "number of results to show")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerSearch error parsing arguments: %w", err)
+	}
 
-		log.Printf("Waiting %s to fetch next feed.\n", duration.String())
+	if *limit < 1 {
+		return fmt.Errorf("handlerSearch limit must be positive")
 	}
-}
 
-// browse the downloaded posts.
-func handlerBrowse(s *state, c command, user database.User) error {
-	var limit int
-	if err := checkNumArgs(c.arguments, 1); err != nil {
-		limit = 2
-	} else {
-		limit, err = strconv.Atoi(c.arguments[0])
-		if err != nil {
-			return err
-		}
+	// every argument after the flags is part of the query
+	searchTerms := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(searchTerms) == "" {
+		fmt.Println("search requires a query, e.g. 'search postgres indexes'.")
+		os.Exit(1)
 	}
 
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int64(limit),
+	results, err := s.db.SearchPostsForUser(context.Background(), database.SearchPostsForUserParams{
+		SearchTerms: searchTerms,
+		UserID:      user.ID,
+		PostLimit:   int64(*limit),
 	})
 	if err != nil {
-		log.Fatalf("Unable to fetch posts from database: %s", err)
+		return fmt.Errorf("handlerSearch error searching posts: %w", err)
 	}
-	log.Printf("Fetched %d posts from database\n", limit)
 
-	fmt.Printf("Showing %d posts:\n", limit)
-	for _, post := range posts {
-		fmt.Printf(" * %s * \n", post.Title)
-		fmt.Printf(" * Published at: %s\n", post.PublishedAt.Time.String())
-		fmt.Printf("%s\n\n", post.Description.String)
+	if s.output != "" {
+		var searchResultOutputs []searchResultOutput
+		for _, result := range results {
+			searchResultOutputs = append(searchResultOutputs, searchResultOutput{
+				ID:          result.ID.String(),
+				Title:       result.Title,
+				URL:         result.Url,
+				FeedName:    result.FeedName,
+				PublishedAt: optionalTime(result.PublishedAt.Time, result.PublishedAt.Valid),
+				Rank:        result.Rank,
+				Snippet:     result.Snippet,
+			})
+		}
+
+		header := []string{"id", "title", "url", "feed_name", "published_at", "rank", "snippet"}
+		return writeListing(s.output, searchResultOutputs, header, func(r searchResultOutput) []string {
+			snippet := r.Snippet
+			if s.output == outputTable {
+				snippet = singleLine(snippet)
+			}
+			return []string{r.ID, r.Title, r.URL, r.FeedName, formatOptionalTime(r.PublishedAt),
+				strconv.FormatFloat(float64(r.Rank), 'f', -1, 32), snippet}
+		})
+	}
+
+	fmt.Printf("Found %d posts matching '%s':\n", len(results), searchTerms)
+	for _, result := range results {
+		fmt.Printf(" * %s * \n", result.Title)
+		fmt.Printf(" * ID: %s\n", result.ID)
+		fmt.Printf(" * Feed: %s\n", result.FeedName)
+		fmt.Printf(" * URL: %s\n", result.Url)
+		fmt.Printf(" * Published at: %s\n", result.PublishedAt.Time.String())
+		fmt.Printf("%s\n\n", result.Snippet)
 	}
 
 	return nil
 }
 
-// prints out a list of feeds in the database
-func handlerFeeds(s *state, c command) error {
-	if err := checkNumArgs(c.arguments, 0); err != nil {
+// serves the JSON API over HTTP
+func handlerServe(s *state, c command) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	if err := flags.Parse(c.arguments); err != nil {
+		return fmt.Errorf("handlerServe error parsing arguments: %w", err)
+	}
+
+	err := serveAPI(s, *addr)
+	if err != nil {
+		return fmt.Errorf("handlerServe error serving API: %w", err)
+	}
+
+	return nil
+}
+
+// sets how often a feed you added is fetched
+func handlerSetInterval(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 2); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	feeds, err := s.db.GetAllFeeds(context.Background())
+	fetchInterval, err := parseFetchInterval(c.arguments[1])
 	if err != nil {
-		return fmt.Errorf("handlerFeeds error fetching all feeds: %w", err)
+		return fmt.Errorf("handlerSetInterval error parsing interval: %w", err)
 	}
 
-	fmt.Printf("Feeds that have been added:\n")
+	feedRecord, err := getOwnedFeed(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerSetInterval error: %w", err)
+	}
 
-	for i, feed := range feeds {
-		user, err := s.db.GetUserByID(context.Background(), feed.UserID)
-		if err != nil {
-			return fmt.Errorf("unable to get user by id: %w", err)
-		}
+	err = s.db.SetFeedInterval(context.Background(), database.SetFeedIntervalParams{
+		ID:            feedRecord.ID,
+		FetchInterval: fetchInterval,
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerSetInterval error updating feed: %w", err)
+	}
 
-		fmt.Printf("Feed #%d:\n", i+1)
-		fmt.Printf(" - Added by: %s\n", user.Name)
-		fmt.Printf(" - Name: %s\n", feed.Name)
-		fmt.Printf(" - URL:  %s\n", feed.Url)
-		fmt.Printf("\n")
+	if !fetchInterval.Valid {
+		fmt.Printf("Removed the interval of '%s', it is fetched on every agg tick.\n", feedRecord.Name)
+		return nil
 	}
 
+	interval := time.Duration(fetchInterval.Int32) * time.Second
+	fmt.Printf("'%s' will be fetched every %s.\n", feedRecord.Name, interval.String())
 	return nil
 }
 
-// as the current user, follows a feed
-// prints the name of the feed and the current user
-func handlerFollow(s *state, c command, user database.User) error {
+// sets the password of another user, e.g. one registered before passwords existed
+func handlerSetPassword(s *state, c command, user database.User) error {
 	if err := checkNumArgs(c.arguments, 1); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	URL := c.arguments[0]
-	feedRecord, err := s.db.GetFeedByURL(context.Background(), URL)
+	username := strings.ToLower(c.arguments[0])
+	userRecord, err := s.db.GetUserByName(context.Background(), username)
 	if err == sql.ErrNoRows {
-		log.Printf("Unable to find the feed using a URL.\n")
-		fmt.Printf("You may need to add the feed first, with 'addfeed'.\n")
+		fmt.Printf("User '%s' does not exists.\n", username)
 		os.Exit(1)
 	} else if err != nil {
-		return fmt.Errorf("handlerfollow error fetching feed by url: %w", err)
+		return fmt.Errorf("handlerSetPassword error fetching user by name: %w", err)
 	}
 
-	_, err = s.db.CreateFeedFollow(context.Background(),
-		database.CreateFeedFollowParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			UserID:    user.ID,
-			FeedID:    feedRecord.ID,
-		})
+	hashedPassword, err := readNewPassword()
 	if err != nil {
-		return fmt.Errorf("handlerFollow error creating feed follow record: %w", err)
-	}
-
-	fmt.Printf("User %s is now following %s\n", user.Name, feedRecord.Name)
-	return nil
-}
-
-// prints out a list of all feeds the current user is following
-func handlerFollowing(s *state, c command, user database.User) error {
-	if err := checkNumArgs(c.arguments, 0); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	feedFollowRecords, err := s.db.GetFeedFollowForUser(context.Background(), user.ID)
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:             userRecord.ID,
+		HashedPassword: hashedPassword,
+		UpdatedAt:      time.Now(),
+	})
 	if err != nil {
-		return fmt.Errorf("handlerFollowing error fetching users feeds by user id: %w", err)
-	}
-
-	fmt.Printf("User %s is following these feeds:\n", user.Name)
-	for _, feedFollowRecord := range feedFollowRecords {
-		feedRecord, err := s.db.GetFeedByID(context.Background(), feedFollowRecord.FeedID)
-		if err != nil {
-			return fmt.Errorf("unable to retrieve feed record: %w", err)
-		}
-
-		fmt.Printf(" - %s\n", feedRecord.Name)
+		return fmt.Errorf("handlerSetPassword error setting password: %w", err)
 	}
-	return nil
-}
 
-// prints out valid commands
-func handlerHelp(_ *state, c command) error {
-	if err := checkNumArgs(c.arguments, 0); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// the account may have been taken over, so its sessions and feed token stop working.
+	// the session of the admin is kept, in case they set their own password.
+	ended, err := endOtherSessions(context.Background(), s, userRecord.ID, s.cfg.SessionToken)
+	if err != nil {
+		return fmt.Errorf("handlerSetPassword error: %w", err)
 	}
 
-	fmt.Println("Available commands:")
-	for cName, cDesc := range validCommands {
-		fmt.Printf(" - %s: %s\n", cName, cDesc)
+	err = s.db.DeleteFeedTokenForUser(context.Background(), userRecord.ID)
+	if err != nil {
+		return fmt.Errorf("handlerSetPassword error deleting feed token: %w", err)
 	}
-	fmt.Println("")
 
+	fmt.Printf("Set the password of '%s', and logged out %d session(s).\n", username, ended)
 	return nil
 }
 
-// logs in a given user
-// sets the given user within the configuration json
-func handlerLogin(s *state, c command) error {
+// stars a post for the current user, to save it for later
+func handlerStar(s *state, c command, user database.User) error {
 	if err := checkNumArgs(c.arguments, 1); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	username := c.arguments[0]
-	username = strings.ToLower(username)
-
-	// check database for user
-	dbFoundUser, _ := s.db.GetUserByName(context.Background(), username)
-	if dbFoundUser.Name != username { // user not in database
-		log.Printf("Unable to find record in database for %s.\n", username)
-		fmt.Printf("User '%s' does not exists.\n", username)
-		os.Exit(1)
+	postRecord, err := getPost(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerStar error: %w", err)
 	}
 
-	err := s.cfg.SetUser(username)
+	now := time.Now()
+	_, err = s.db.StarPost(context.Background(), database.StarPostParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		PostID:    postRecord.ID,
+		StarredAt: sql.NullTime{Time: now, Valid: true},
+	})
 	if err != nil {
-		return fmt.Errorf("handlerLogin error setting username in config: %w", err)
+		return fmt.Errorf("handlerStar error starring post: %w", err)
 	}
 
-	fmt.Printf("Logged into username:'%v' successfully.\n", username)
+	fmt.Printf("Starred '%s'.\n", postRecord.Title)
 	return nil
 }
 
-// registers a new user
-func handlerRegister(s *state, c command) error {
-	if err := checkNumArgs(c.arguments, 1); err != nil {
+// prints out the posts the current user has starred
+func handlerStarred(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 0); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// name processing
-	username := c.arguments[0]
-	username = strings.ToLower(username)
-
-	// check in the DB for existing user
-	dbFoundUser, err := s.db.GetUserByName(context.Background(), username)
-	if dbFoundUser.Name == username {
-		fmt.Printf("User '%s' already exists.\n", username)
-		os.Exit(1)
+	posts, err := s.db.GetStarredPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("handlerStarred error fetching starred posts: %w", err)
 	}
 
-	// if username does not exist create a new user in the database
-	dbUser, err := s.db.CreateUser(context.Background(),
-		database.CreateUserParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Name:      username,
+	if s.output != "" {
+		var starredPostOutputs []starredPostOutput
+		for _, post := range posts {
+			starredPostOutputs = append(starredPostOutputs, starredPostOutput{
+				ID:          post.ID.String(),
+				Title:       post.Title,
+				URL:         post.Url,
+				Description: post.Description.String,
+				Author:      post.Author.String,
+				FeedID:      post.FeedID.String(),
+				PublishedAt: optionalTime(post.PublishedAt.Time, post.PublishedAt.Valid),
+				StarredAt:   optionalTime(post.StarredAt.Time, post.StarredAt.Valid),
+			})
+		}
+
+		header := []string{"id", "title", "url", "description", "author",
+			"feed_id", "published_at", "starred_at"}
+		return writeListing(s.output, starredPostOutputs, header, func(p starredPostOutput) []string {
+			description := p.Description
+			if s.output == outputTable {
+				description = singleLine(description)
+			}
+			return []string{p.ID, p.Title, p.URL, description, p.Author,
+				p.FeedID, formatOptionalTime(p.PublishedAt), formatOptionalTime(p.StarredAt)}
 		})
-	if err != nil {
-		fmt.Printf("Error inserting new user: %s\n", err)
-		os.Exit(1)
 	}
 
-	// changes to this new user in the config
-	s.cfg.SetUser(username)
-	fmt.Printf("New user was created: '%s'\nUser: %+v\n", username, dbUser)
+	fmt.Printf("Showing %d starred posts:\n", len(posts))
+	for _, post := range posts {
+		fmt.Printf(" ★ %s * \n", post.Title)
+		fmt.Printf(" * ID: %s\n", post.ID)
+		fmt.Printf(" * URL: %s\n", post.Url)
+		fmt.Printf(" * Starred at: %s\n", post.StarredAt.Time.String())
+		fmt.Printf("%s\n\n", post.Description.String)
+	}
+
 	return nil
 }
 
-// resets database by deleting all records on user table
-// this will delete the records in the feeds table as well.
-func handlerReset(s *state, c command) error {
-	if err := checkNumArgs(c.arguments, 0); err != nil {
+// moves a feed you follow out of its folder
+func handlerUncategorize(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	err := s.db.ResetUsers(context.Background())
+	feedRecord, err := setFeedFolder(s, c.arguments[0], uuid.NullUUID{}, user)
 	if err != nil {
-		return fmt.Errorf("handlerReset was unable to reset the users table: %w", err)
+		return fmt.Errorf("handlerUncategorize error: %w", err)
 	}
 
-	fmt.Println("Reset 'user' table successfully.")
+	fmt.Printf("Moved '%s' out of its folder.\n", feedRecord.Name)
 	return nil
 }
 
@@ -583,7 +2373,7 @@ func handlerUnfollow(s *state, c command, user database.User) error {
 
 	// URL assumed to be first item in list
 	URL := c.arguments[0]
-	feedRecord, err := s.db.GetFeedByURL(context.Background(), URL)
+	feedRecord, err := getFeedByURL(context.Background(), s.db, URL)
 	// TODO: Look into swapping to psql error check
 	if err == sql.ErrNoRows {
 		fmt.Printf("Unable to find the feed by URL.\n")
@@ -606,6 +2396,70 @@ func handlerUnfollow(s *state, c command, user database.User) error {
 	return nil
 }
 
+// removes the star from a post for the current user
+func handlerUnstar(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 1); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	postRecord, err := getPost(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerUnstar error: %w", err)
+	}
+
+	_, err = s.db.UnstarPost(context.Background(), database.UnstarPostParams{
+		UserID:    user.ID,
+		PostID:    postRecord.ID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("handlerUnstar error removing star: %w", err)
+	}
+
+	fmt.Printf("Removed the star from '%s'.\n", postRecord.Title)
+	return nil
+}
+
+// changes the URL of a feed you added, keeping its posts and follows
+func handlerUpdateFeedURL(s *state, c command, user database.User) error {
+	if err := checkNumArgs(c.arguments, 2); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	feedRecord, err := getOwnedFeed(s, c.arguments[0], user)
+	if err != nil {
+		return fmt.Errorf("handlerUpdateFeedURL error: %w", err)
+	}
+
+	// URLs that only differ from another feeds URL by normalization are rejected too
+	newURL := normalizeURL(c.arguments[1])
+	existingFeed, err := getFeedByURL(context.Background(), s.db, newURL)
+	if err == nil && existingFeed.ID != feedRecord.ID {
+		fmt.Printf("Another feed already uses the URL '%s'.\n", existingFeed.Url)
+		os.Exit(1)
+	} else if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("handlerUpdateFeedURL error fetching feed by url: %w", err)
+	}
+
+	// the cache headers and failures of the old URL are cleared
+	err = s.db.SetFeedURL(context.Background(), database.SetFeedURLParams{
+		ID:        feedRecord.ID,
+		Url:       newURL,
+		UpdatedAt: time.Now(),
+	})
+	if isUniqueViolation(err) {
+		fmt.Printf("Another feed already uses the URL '%s'.\n", newURL)
+		os.Exit(1)
+	} else if err != nil {
+		return fmt.Errorf("handlerUpdateFeedURL error setting url: %w", err)
+	}
+
+	fmt.Printf("Changed the URL of '%s' to '%s' successfully.\n", feedRecord.Name, newURL)
+	return nil
+}
+
 // shows a list of all users from database,
 // as well as the current logged in user
 func handlerUsers(s *state, c command) error {
@@ -614,13 +2468,37 @@ func handlerUsers(s *state, c command) error {
 		os.Exit(1)
 	}
 
-	currentName := s.cfg.CurrentUsername
+	// no user is current when the session is missing or expired
+	currentName := ""
+	currentUser, err := sessionUser(context.Background(), s, s.cfg.SessionToken)
+	if err == nil {
+		currentName = currentUser.Name
+	}
 
 	dbUsers, err := s.db.GetUsers(context.Background())
 	if err != nil {
 		return fmt.Errorf("unable to query list of users in database: %w", err)
 	}
 
+	if s.output != "" {
+		var userOutputs []userOutput
+		for _, user := range dbUsers {
+			userOutputs = append(userOutputs, userOutput{
+				ID:        user.ID.String(),
+				Name:      user.Name,
+				CreatedAt: user.CreatedAt,
+				Current:   user.Name == currentName,
+				Admin:     user.IsAdmin,
+			})
+		}
+
+		header := []string{"id", "name", "created_at", "current", "admin"}
+		return writeListing(s.output, userOutputs, header, func(u userOutput) []string {
+			return []string{u.ID, u.Name, u.CreatedAt.Format(time.RFC3339),
+				strconv.FormatBool(u.Current), strconv.FormatBool(u.Admin)}
+		})
+	}
+
 	if len(dbUsers) == 0 {
 		log.Println("There are currently no registered users.")
 		fmt.Println("You may need to register first with 'register'.")
@@ -629,7 +2507,10 @@ func handlerUsers(s *state, c command) error {
 
 	for _, user := range dbUsers {
 		name := user.Name
-		if name == currentName {
+		if user.IsAdmin {
+			name += " (admin)"
+		}
+		if user.Name == currentName {
 			name += " (current)"
 		}
 
@@ -702,38 +2583,80 @@ func main() {
 	// setting up program state
 	dbQueries := database.New(db)
 	state := state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:   dbQueries,
+		conn: db,
+		cfg:  &cfg,
 	}
 
 	// registering commands
 	cmds := newCommands()
 	cmds.registerCommand("addfeed", middlewareLoggedIn(handlerAddFeed))
+	cmds.registerCommand("addfolder", middlewareLoggedIn(handlerAddFolder))
 	cmds.registerCommand("agg", handlerAgg)
 	cmds.registerCommand("browse", middlewareLoggedIn(handlerBrowse))
+	cmds.registerCommand("categorize", middlewareLoggedIn(handlerCategorize))
+	cmds.registerCommand("demote", middlewareAdmin(handlerDemote))
+	cmds.registerCommand("disablefeed", middlewareLoggedIn(handlerDisableFeed))
+	cmds.registerCommand("enablefeed", middlewareLoggedIn(handlerEnableFeed))
+	cmds.registerCommand("export", middlewareLoggedIn(handlerExport))
 	cmds.registerCommand("feeds", handlerFeeds)
+	cmds.registerCommand("feedstatus", handlerFeedStatus)
+	cmds.registerCommand("feedtoken", middlewareLoggedIn(handlerFeedToken))
+	cmds.registerCommand("folders", middlewareLoggedIn(handlerFolders))
 	cmds.registerCommand("follow", middlewareLoggedIn(handlerFollow))
 	cmds.registerCommand("following", middlewareLoggedIn(handlerFollowing))
 	cmds.registerCommand("help", handlerHelp)
+	cmds.registerCommand("import", middlewareLoggedIn(handlerImport))
 	cmds.registerCommand("login", handlerLogin)
+	cmds.registerCommand("logout", middlewareLoggedIn(handlerLogout))
+	cmds.registerCommand("markread", middlewareLoggedIn(handlerMarkRead))
+	cmds.registerCommand("markunread", middlewareLoggedIn(handlerMarkUnread))
+	cmds.registerCommand("passwd", middlewareLoggedIn(handlerPasswd))
+	cmds.registerCommand("promote", middlewareAdmin(handlerPromote))
+	cmds.registerCommand("publish", middlewareLoggedIn(handlerPublish))
 	cmds.registerCommand("register", handlerRegister)
-	cmds.registerCommand("reset", handlerReset)
+	cmds.registerCommand("removefeed", middlewareLoggedIn(handlerRemoveFeed))
+	cmds.registerCommand("removefolder", middlewareLoggedIn(handlerRemoveFolder))
+	cmds.registerCommand("renamefeed", middlewareLoggedIn(handlerRenameFeed))
+	cmds.registerCommand("renamefolder", middlewareLoggedIn(handlerRenameFolder))
+	cmds.registerCommand("reset", middlewareAdmin(handlerReset))
+	cmds.registerCommand("search", middlewareLoggedIn(handlerSearch))
+	cmds.registerCommand("serve", handlerServe)
+	cmds.registerCommand("setinterval", middlewareLoggedIn(handlerSetInterval))
+	cmds.registerCommand("setpassword", middlewareAdmin(handlerSetPassword))
+	cmds.registerCommand("star", middlewareLoggedIn(handlerStar))
+	cmds.registerCommand("starred", middlewareLoggedIn(handlerStarred))
+	cmds.registerCommand("uncategorize", middlewareLoggedIn(handlerUncategorize))
 	cmds.registerCommand("unfollow", middlewareLoggedIn(handlerUnfollow))
+	cmds.registerCommand("unstar", middlewareLoggedIn(handlerUnstar))
+	cmds.registerCommand("updatefeedurl", middlewareLoggedIn(handlerUpdateFeedURL))
 	cmds.registerCommand("users", handlerUsers)
 
 	// processing arguments
 	// set to require 2 arguments, command and string
 	// e.g. "register <name>", "login <name>"
-	args := os.Args
+	// the global --output flag is removed before the command is parsed
+	args, output, err := parseOutputFlag(os.Args[1:])
+	if err != nil {
+		fmt.Printf("Error occured: %v\n", err)
+		os.Exit(1)
+	}
+	state.output = output
+
 	numArgs := len(args)
-	if numArgs < 2 {
-		fmt.Printf("Not enough arguments provided: %d\nArgs: %v\n", numArgs-1, args[1:])
+	if numArgs < 1 {
+		fmt.Printf("Not enough arguments provided: %d\nArgs: %v\n", numArgs, args)
 		os.Exit(1)
 	}
 
 	cmd := command{
-		name:      args[1],  // command name
-		arguments: args[2:], // inclusive of the arguments after command name
+		name:      args[0],  // command name
+		arguments: args[1:], // inclusive of the arguments after command name
+	}
+
+	if state.output != "" && !listingCommands[cmd.name] {
+		fmt.Printf("--output is only supported by the listing commands, not '%s'.\n", cmd.name)
+		os.Exit(1)
 	}
 
 	// runs the command
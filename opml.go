@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ==========
+// OPML TYPES
+// ==========
+
+// OPML document, a list of subscriptions exported by feed readers
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type OPMLBody struct {
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// One outline, either a subscription with an xmlUrl or a folder of outlines
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// ==============
+// OPML FUNCTIONS
+// ==============
+
+// reads and decodes an OPML file
+func readOPML(path string) (OPML, error) {
+	rawOPML, err := os.ReadFile(path)
+	if err != nil {
+		return OPML{}, err
+	}
+
+	var opml OPML
+	err = xml.Unmarshal(rawOPML, &opml)
+	if err != nil {
+		return OPML{}, fmt.Errorf("unable to decode opml: %w", err)
+	}
+
+	return opml, nil
+}
+
+// encodes an OPML document, and writes it to the file at path.
+// the document is written to stdout when no path is given.
+func writeOPML(opml OPML, path string) error {
+	rawOPML, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode opml: %w", err)
+	}
+
+	rawOPML = append([]byte(xml.Header), rawOPML...)
+	rawOPML = append(rawOPML, '\n')
+
+	if path == "" {
+		_, err = os.Stdout.Write(rawOPML)
+		return err
+	}
+
+	return os.WriteFile(path, rawOPML, 0644)
+}
+
+// returns a subscription outline for a feed
+func newSubscriptionOutline(name, URL string) OPMLOutline {
+	return OPMLOutline{
+		Text:   name,
+		Title:  name,
+		Type:   "rss",
+		XMLURL: URL,
+	}
+}
+
+// returns every subscription outline, walking into nested folders
+func subscriptionOutlines(outlines []OPMLOutline) []OPMLOutline {
+	var subscriptions []OPMLOutline
+	for _, outline := range outlines {
+		if strings.TrimSpace(outline.XMLURL) != "" {
+			subscriptions = append(subscriptions, outline)
+		}
+
+		subscriptions = append(subscriptions, subscriptionOutlines(outline.Outlines)...)
+	}
+
+	return subscriptions
+}
+
+// returns the name of a subscription, falling back to its URL
+func (o OPMLOutline) name() string {
+	if title := strings.TrimSpace(o.Title); title != "" {
+		return title
+	}
+	if text := strings.TrimSpace(o.Text); text != "" {
+		return text
+	}
+
+	return strings.TrimSpace(o.XMLURL)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// machine readable output formats, set with the global --output flag.
+// when no format is given, commands print their usual text.
+const (
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTable = "table"
+)
+
+// commands that list items, and so accept the global --output flag
+var listingCommands = map[string]bool{
+	"browse":     true,
+	"feeds":      true,
+	"feedstatus": true,
+	"folders":    true,
+	"following":  true,
+	"search":     true,
+	"starred":    true,
+	"users":      true,
+}
+
+// ============
+// OUTPUT TYPES
+// ============
+
+// stable json schema of a user
+type userOutput struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+	Admin     bool      `json:"admin"`
+}
+
+// stable json schema of a feed
+type feedOutput struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	AddedBy       string     `json:"added_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+// stable json schema of a feed that is failing to be fetched
+type feedStatusOutput struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	Disabled            bool       `json:"disabled"`
+	LastError           string     `json:"last_error"`
+	LastErrorAt         *time.Time `json:"last_error_at"`
+}
+
+// stable json schema of a folder
+type folderOutput struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	FeedCount int64     `json:"feed_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// stable json schema of a followed feed
+type followOutput struct {
+	FeedID     string    `json:"feed_id"`
+	FeedName   string    `json:"feed_name"`
+	FeedURL    string    `json:"feed_url"`
+	Folder     string    `json:"folder"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// stable json schema of a post
+type postOutput struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Author      string     `json:"author"`
+	FeedID      string     `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	PublishedAt *time.Time `json:"published_at"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+}
+
+// stable json schema of a search result, the snippet highlights matches in [brackets]
+type searchResultOutput struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	FeedName    string     `json:"feed_name"`
+	PublishedAt *time.Time `json:"published_at"`
+	Rank        float32    `json:"rank"`
+	Snippet     string     `json:"snippet"`
+}
+
+// stable json schema of a starred post
+type starredPostOutput struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Author      string     `json:"author"`
+	FeedID      string     `json:"feed_id"`
+	PublishedAt *time.Time `json:"published_at"`
+	StarredAt   *time.Time `json:"starred_at"`
+}
+
+// ================
+// OUTPUT FUNCTIONS
+// ================
+
+// removes the global --output flag from the arguments, and returns its format.
+// the flag is given before the command, e.g. "--output json" or "--output=json",
+// so that commands are free to define their own --output flag.
+func parseOutputFlag(args []string) ([]string, string, error) {
+	format := ""
+	i := 0
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if value, found := strings.CutPrefix(arg, "--output="); found {
+			format = value
+			continue
+		}
+		if arg == "--output" {
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--output requires a format: json, csv or table")
+			}
+			format = args[i+1]
+			i++
+			continue
+		}
+
+		// the first other argument is the command
+		break
+	}
+
+	switch format {
+	case "", outputJSON, outputCSV, outputTable:
+		return args[i:], format, nil
+	}
+
+	return nil, "", fmt.Errorf("unknown output format '%s', use json, csv or table", format)
+}
+
+// writes items to stdout in the given format.
+// json uses the items fields, csv and table use the header and record function.
+func writeListing[T any](format string, items []T, header []string, record func(T) []string) error {
+	switch format {
+	case outputJSON:
+		// an empty listing is an empty array, rather than null
+		if items == nil {
+			items = []T{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+
+	case outputCSV:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write(header)
+		for _, item := range items {
+			writer.Write(record(item))
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case outputTable:
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, item := range items {
+			fmt.Fprintln(writer, strings.Join(record(item), "\t"))
+		}
+		return writer.Flush()
+	}
+
+	return fmt.Errorf("unknown output format '%s'", format)
+}
+
+// formats an optional time for csv and table output
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// returns a pointer to the time, or nil when it is not valid
+func optionalTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+
+	return &t
+}
+
+// keeps descriptions on one line, for table output
+func singleLine(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
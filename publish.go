@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicholasss/gator/internal/database"
+)
+
+// number of posts in a published feed, when no limit is given
+const defaultPublishLimit = 50
+
+// =================
+// PUBLISH FUNCTIONS
+// =================
+
+// builds a single Atom feed of the posts from every feed the user follows.
+// the selfURL is where the feed is served from, and is left out when empty.
+func newUserAtomFeed(ctx context.Context, s *state, user database.User, params database.GetPostsForUserParams, selfURL string) (AtomFeed, error) {
+	posts, err := s.db.GetPostsForUser(ctx, params)
+	if err != nil {
+		return AtomFeed{}, fmt.Errorf("unable to fetch posts: %w", err)
+	}
+
+	atomFeed := AtomFeed{
+		ID:       "urn:uuid:" + user.ID.String(),
+		Title:    AtomText{Text: fmt.Sprintf("%s's gator river", user.Name)},
+		Subtitle: &AtomText{Text: fmt.Sprintf("Posts from the feeds %s follows", user.Name)},
+	}
+	if selfURL != "" {
+		atomFeed.Links = append(atomFeed.Links, AtomLink{
+			Href: selfURL,
+			Rel:  "self",
+			Type: "application/atom+xml",
+		})
+	}
+
+	// feed names are the fallback author of posts
+	feedNames := make(map[uuid.UUID]string)
+	updated := user.CreatedAt
+	for _, post := range posts {
+		feedName, ok := feedNames[post.FeedID]
+		if !ok {
+			feedRecord, err := s.db.GetFeedByID(ctx, post.FeedID)
+			if err != nil {
+				return AtomFeed{}, fmt.Errorf("unable to fetch feed of post: %w", err)
+			}
+			feedName = feedRecord.Name
+			feedNames[post.FeedID] = feedName
+		}
+
+		author := feedName
+		if post.Author.Valid && post.Author.String != "" {
+			author = post.Author.String
+		}
+
+		entry := AtomEntry{
+			ID:      "urn:uuid:" + post.ID.String(),
+			Title:   AtomText{Text: post.Title},
+			Links:   []AtomLink{{Href: post.Url, Rel: "alternate"}},
+			Author:  &AtomPerson{Name: author},
+			Updated: post.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if post.Description.Valid && post.Description.String != "" {
+			entry.Summary = &AtomText{Type: "html", Text: post.Description.String}
+		}
+		if post.PublishedAt.Valid {
+			entry.Published = post.PublishedAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		atomFeed.Entries = append(atomFeed.Entries, entry)
+		if post.UpdatedAt.After(updated) {
+			updated = post.UpdatedAt
+		}
+	}
+	atomFeed.Updated = updated.UTC().Format(time.RFC3339)
+
+	return atomFeed, nil
+}
+
+// returns the API path of the published feed of a user, read with a feed token
+func userFeedPath(user database.User, feedToken string) string {
+	return "/api/users/" + url.PathEscape(user.Name) + "/feed.atom?token=" + url.QueryEscape(feedToken)
+}
+
+// encodes an Atom feed document
+func encodeAtomFeed(atomFeed AtomFeed) ([]byte, error) {
+	rawFeed, err := xml.MarshalIndent(atomFeed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode atom feed: %w", err)
+	}
+
+	rawFeed = append([]byte(xml.Header), rawFeed...)
+	rawFeed = append(rawFeed, '\n')
+	return rawFeed, nil
+}
+
+// encodes an Atom feed, and writes it to the file at path.
+// the feed is written to stdout when no path is given.
+func writeAtomFeed(atomFeed AtomFeed, path string) error {
+	rawFeed, err := encodeAtomFeed(atomFeed)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		_, err = os.Stdout.Write(rawFeed)
+		return err
+	}
+
+	return os.WriteFile(path, rawFeed, 0644)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/xml"
+)
+
+// namespace of the root element of RSS 1.0 documents
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// =========
+// RDF TYPES
+// =========
+
+// RSS 1.0 feed, the items are siblings of the channel instead of children
+type RDFFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Items []RSSItem `xml:"item"`
+}
+
+// =============
+// RDF FUNCTIONS
+// =============
+
+// decodes an RSS 1.0 document and maps it into the RSSFeed shape
+func parseRDFFeed(rawFeed []byte) (*RSSFeed, error) {
+	var rdfFeed RDFFeed
+	err := xml.Unmarshal(rawFeed, &rdfFeed)
+	if err != nil {
+		return &RSSFeed{}, err
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = rdfFeed.Channel.Title
+	feed.Channel.Link = rdfFeed.Channel.Link
+	feed.Channel.Description = rdfFeed.Channel.Description
+	feed.Channel.Items = rdfFeed.Items
+
+	return &feed, nil
+}
+
+// fills in the date and author of items from their Dublin Core fields,
+// for items that did not provide a pubDate or author element.
+func applyDublinCore(feed *RSSFeed) {
+	for i := range feed.Channel.Items {
+		item := &feed.Channel.Items[i]
+		if item.PubDate == "" {
+			item.PubDate = item.DCDate
+		}
+		if item.Author == "" {
+			item.Author = item.DCCreator
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nicholasss/gator/internal/database"
+)
+
+// ====================
+// SCHEDULING FUNCTIONS
+// ====================
+
+// returns the max-age of a Cache-Control header, or zero when there is none
+func cacheControlMaxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		value, found := strings.CutPrefix(directive, "max-age=")
+		if !found {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	return 0
+}
+
+// returns the RSS ttl of a feed, given in minutes, or zero when there is none
+func feedTTL(feed *RSSFeed) time.Duration {
+	minutes, err := strconv.Atoi(strings.TrimSpace(feed.Channel.TTL))
+	if err != nil || minutes < 0 {
+		return 0
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// checks the RSS skipHours and skipDays of a feed, which are given in GMT
+func isSkippedTime(feed *RSSFeed, t time.Time) bool {
+	t = t.UTC()
+	for _, hour := range feed.Channel.SkipHours {
+		skipped, err := strconv.Atoi(strings.TrimSpace(hour))
+		if err == nil && skipped%24 == t.Hour() {
+			return true
+		}
+	}
+
+	for _, day := range feed.Channel.SkipDays {
+		if strings.EqualFold(strings.TrimSpace(day), t.Weekday().String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns the ttl, skipHours and skipDays of a fetched feed, for saving with the feed
+func scheduleHints(feedID uuid.UUID, feed *RSSFeed) database.SetFeedScheduleHintsParams {
+	params := database.SetFeedScheduleHintsParams{ID: feedID}
+	if ttl := feedTTL(feed); ttl > 0 {
+		params.Ttl = sql.NullInt32{Int32: int32(ttl / time.Minute), Valid: true}
+	}
+
+	var hours, days []string
+	for _, hour := range feed.Channel.SkipHours {
+		if hour = strings.TrimSpace(hour); hour != "" {
+			hours = append(hours, hour)
+		}
+	}
+	for _, day := range feed.Channel.SkipDays {
+		if day = strings.TrimSpace(day); day != "" {
+			days = append(days, day)
+		}
+	}
+	params.SkipHours = sql.NullString{String: strings.Join(hours, ","), Valid: len(hours) > 0}
+	params.SkipDays = sql.NullString{String: strings.Join(days, ","), Valid: len(days) > 0}
+
+	return params
+}
+
+// rebuilds the hints saved with a feed, as an unmodified feed has no document to read them from
+func savedScheduleHints(feedRecord database.Feed) *RSSFeed {
+	feed := &RSSFeed{}
+	if feedRecord.Ttl.Valid {
+		feed.Channel.TTL = strconv.Itoa(int(feedRecord.Ttl.Int32))
+	}
+	if feedRecord.SkipHours.Valid {
+		feed.Channel.SkipHours = strings.Split(feedRecord.SkipHours.String, ",")
+	}
+	if feedRecord.SkipDays.Valid {
+		feed.Channel.SkipDays = strings.Split(feedRecord.SkipDays.String, ",")
+	}
+
+	return feed
+}
+
+// calculates when a feed should be fetched again.
+// the longest of the feeds own interval, its ttl and the max-age is waited,
+// then the time is moved past any hours or days the feed asks to skip.
+func nextFetchTime(now time.Time, interval time.Duration, feed *RSSFeed, cache feedCache) time.Time {
+	wait := max(interval, feedTTL(feed), cache.MaxAge)
+	next := now.Add(wait)
+
+	// a week of hours covers every combination of skipped hours and days
+	for range 24 * 7 {
+		if !isSkippedTime(feed, next) {
+			return next
+		}
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	// every hour is skipped, so the hints are ignored
+	return now.Add(wait)
+}
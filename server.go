@@ -0,0 +1,727 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nicholasss/gator/internal/database"
+)
+
+// scheme of the Authorization header, followed by a session token
+const apiBearerPrefix = "Bearer "
+
+// most posts returned by a single API request
+const apiMaxPostLimit = 100
+
+// =========
+// API TYPES
+// =========
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type apiCreateUserRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type apiLoginRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type apiSessionResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type apiFeedTokenResponse struct {
+	Token   string `json:"token"`
+	FeedURL string `json:"feed_url"`
+}
+
+type apiCreateFeedRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type apiFollowRequest struct {
+	FeedURL string `json:"feed_url"`
+}
+
+// =============
+// API FUNCTIONS
+// =============
+
+// registers every API route on a new mux
+func newAPIMux(s *state) *http.ServeMux {
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler func(*state, http.ResponseWriter, *http.Request)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			handler(s, w, r)
+		})
+	}
+
+	handle("POST /api/users", apiCreateUser)
+	handle("POST /api/login", apiLogin)
+	handle("POST /api/logout", apiMiddlewareLoggedIn(apiLogout))
+	handle("POST /api/feed_token", apiMiddlewareLoggedIn(apiCreateFeedToken))
+	handle("GET /api/users/{name}/feed.atom", apiMiddlewareFeedReader(apiGetUserFeed))
+	handle("GET /api/feeds", apiGetFeeds)
+	handle("POST /api/feeds", apiMiddlewareLoggedIn(apiCreateFeed))
+	handle("GET /api/follows", apiMiddlewareLoggedIn(apiGetFollows))
+	handle("POST /api/follows", apiMiddlewareLoggedIn(apiCreateFollow))
+	handle("DELETE /api/follows/{feedID}", apiMiddlewareLoggedIn(apiDeleteFollow))
+	handle("GET /api/posts", apiMiddlewareLoggedIn(apiGetPosts))
+	handle("POST /api/posts/{postID}/read", apiMiddlewareLoggedIn(apiMarkPostRead))
+	handle("DELETE /api/posts/{postID}/read", apiMiddlewareLoggedIn(apiMarkPostUnread))
+
+	return mux
+}
+
+// writes the payload as a json response
+func respondWithJSON(w http.ResponseWriter, code int, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding response: %s\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// writes an error message as a json response, server errors are logged
+func respondWithError(w http.ResponseWriter, code int, message string, err error) {
+	if err != nil {
+		log.Printf("API error: %s: %s\n", message, err)
+	}
+
+	respondWithJSON(w, code, apiError{Error: message})
+}
+
+// decodes the json body of a request into payload
+func decodeRequest(r *http.Request, payload any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(payload)
+}
+
+// checks if the error is from a unique constraint in postgres
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == UniqueViolationErr
+}
+
+// converts a post row into its stable json schema
+func newPostOutput(post database.BrowsePostsForUserRow) postOutput {
+	return postOutput{
+		ID:          post.ID.String(),
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description.String,
+		Author:      post.Author.String,
+		FeedID:      post.FeedID.String(),
+		FeedName:    post.FeedName,
+		PublishedAt: optionalTime(post.PublishedAt.Time, post.PublishedAt.Valid),
+		Read:        post.Read,
+		Starred:     post.Starred,
+	}
+}
+
+// ==============
+// API MIDDLEWARE
+// ==============
+
+// returns the session token of a request, from its Authorization header
+func requestSessionToken(r *http.Request) string {
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), apiBearerPrefix); found {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
+
+// Allows for all API handlers that require a user to accept them as an argument.
+// the user is identified by a session token, from login or registering.
+func apiMiddlewareLoggedIn(handler func(*state, http.ResponseWriter, *http.Request, database.User)) func(*state, http.ResponseWriter, *http.Request) {
+	return func(s *state, w http.ResponseWriter, r *http.Request) {
+		userRecord, err := sessionUser(r.Context(), s, requestSessionToken(r))
+		if err == errInvalidSession {
+			respondWithError(w, http.StatusUnauthorized, "missing or expired session token", nil)
+			return
+		} else if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+			return
+		}
+
+		handler(s, w, r, userRecord)
+	}
+}
+
+// Allows the published feed of a user to be read with a session token, or with
+// a read-only feed token in the token query parameter, as feed readers cannot
+// send headers. feed tokens are not accepted by any other route.
+func apiMiddlewareFeedReader(handler func(*state, http.ResponseWriter, *http.Request, database.User)) func(*state, http.ResponseWriter, *http.Request) {
+	return func(s *state, w http.ResponseWriter, r *http.Request) {
+		var userRecord database.User
+		var err error
+		if token := requestSessionToken(r); token != "" {
+			userRecord, err = sessionUser(r.Context(), s, token)
+		} else {
+			userRecord, err = feedTokenUser(r.Context(), s, r.URL.Query().Get("token"))
+		}
+
+		if err == errInvalidSession {
+			respondWithError(w, http.StatusUnauthorized, "missing or invalid token", nil)
+			return
+		} else if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+			return
+		}
+
+		handler(s, w, r, userRecord)
+	}
+}
+
+// ============
+// API HANDLERS
+// ============
+
+// registers a new user
+func apiCreateUser(s *state, w http.ResponseWriter, r *http.Request) {
+	var params apiCreateUserRequest
+	if err := decodeRequest(r, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body", nil)
+		return
+	}
+
+	username := strings.ToLower(strings.TrimSpace(params.Name))
+	if username == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required", nil)
+		return
+	}
+
+	hashedPassword, err := hashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	_, err = s.db.GetUserByName(r.Context(), username)
+	if err == nil {
+		respondWithError(w, http.StatusConflict, "user already exists", nil)
+		return
+	} else if err != sql.ErrNoRows {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+		return
+	}
+
+	// the first user to register becomes the admin
+	userCount, err := s.db.CountUsers(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to count users", err)
+		return
+	}
+
+	dbUser, err := s.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		Name:           username,
+		HashedPassword: hashedPassword,
+		IsAdmin:        userCount == 0,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to create user", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, userOutput{
+		ID:        dbUser.ID.String(),
+		Name:      dbUser.Name,
+		CreatedAt: dbUser.CreatedAt,
+		Admin:     dbUser.IsAdmin,
+	})
+}
+
+// starts a session for a user, returning its token
+func apiLogin(s *state, w http.ResponseWriter, r *http.Request) {
+	var params apiLoginRequest
+	if err := decodeRequest(r, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body", nil)
+		return
+	}
+
+	userRecord, err := s.db.GetUserByName(r.Context(), strings.ToLower(strings.TrimSpace(params.Name)))
+	if err == sql.ErrNoRows {
+		respondWithError(w, http.StatusUnauthorized, "incorrect name or password", nil)
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+		return
+	}
+
+	if !userRecord.HashedPassword.Valid {
+		respondWithError(w, http.StatusUnauthorized, "password not set, ask an admin to set one", nil)
+		return
+	}
+	if !checkPassword(userRecord, params.Password) {
+		respondWithError(w, http.StatusUnauthorized, "incorrect name or password", nil)
+		return
+	}
+
+	token, expiresAt, err := createSession(r.Context(), s, userRecord.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to create session", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, apiSessionResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ends the session used by the request
+func apiLogout(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	err := s.db.DeleteSession(r.Context(), hashSessionToken(requestSessionToken(r)))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to delete session", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// creates a read-only token for the published feed of the user, replacing any earlier one
+func apiCreateFeedToken(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	token, err := createFeedToken(r.Context(), s, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to create feed token", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiFeedTokenResponse{
+		Token:   token,
+		FeedURL: userFeedPath(user, token),
+	})
+}
+
+// publishes the posts from the feeds a user follows as a single Atom feed.
+// accepts limit and unread query parameters.
+func apiGetUserFeed(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	if strings.ToLower(r.PathValue("name")) != user.Name {
+		respondWithError(w, http.StatusForbidden, "feeds are only published to their own user", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.GetPostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: true,
+		PostLimit:   defaultPublishLimit,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > apiMaxPostLimit {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", nil)
+			return
+		}
+		params.PostLimit = int64(value)
+	}
+
+	if unread := query.Get("unread"); unread != "" {
+		value, err := strconv.ParseBool(unread)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "unread must be true or false", nil)
+			return
+		}
+		params.IncludeRead = !value
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	selfURL := scheme + "://" + r.Host + r.URL.RequestURI()
+
+	atomFeed, err := newUserAtomFeed(r.Context(), s, user, params, selfURL)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to build feed", err)
+		return
+	}
+
+	rawFeed, err := encodeAtomFeed(atomFeed)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to encode feed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(rawFeed)
+}
+
+// lists every feed
+func apiGetFeeds(s *state, w http.ResponseWriter, r *http.Request) {
+	feeds, err := s.db.GetAllFeeds(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch feeds", err)
+		return
+	}
+
+	feedOutputs := []feedOutput{}
+	for _, feed := range feeds {
+		user, err := s.db.GetUserByID(r.Context(), feed.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+			return
+		}
+
+		feedOutputs = append(feedOutputs, feedOutput{
+			ID:            feed.ID.String(),
+			Name:          feed.Name,
+			URL:           feed.Url,
+			AddedBy:       user.Name,
+			CreatedAt:     feed.CreatedAt,
+			LastFetchedAt: optionalTime(feed.LastFetchedAt.Time, feed.LastFetchedAt.Valid),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, feedOutputs)
+}
+
+// adds a new feed, and follows it as the user
+func apiCreateFeed(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	var params apiCreateFeedRequest
+	if err := decodeRequest(r, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body", nil)
+		return
+	}
+	if params.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "url is required", nil)
+		return
+	}
+
+	// the URL may be a website, the first feed it links to is added
+	_, err := getFeedByURL(r.Context(), s.db, params.URL)
+	if err == sql.ErrNoRows {
+		ctx, cancel := context.WithTimeout(r.Context(), discoveryTimeout)
+		defer cancel()
+		discovered, feed, err := resolveFeed(ctx, publicFeedClient, params.URL, func(feeds []discoveredFeed) discoveredFeed {
+			return feeds[0]
+		})
+		if err != nil {
+			// the reason is only logged, so that callers cannot use it to probe networks
+			respondWithError(w, http.StatusUnprocessableEntity, "unable to find a feed at the url", err)
+			return
+		}
+
+		params.URL = discovered.URL
+		if params.Name == "" {
+			params.Name = feedName(feed, discovered)
+		}
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch feed", err)
+		return
+	}
+
+	feedRecord, created, followed, err := addAndFollowFeed(r.Context(), s, database.CreateFeedParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		Name:       params.Name,
+		Url:        params.URL,
+		UserID:     user.ID,
+		PublicOnly: true,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to add feed", err)
+		return
+	}
+	if !followed {
+		respondWithError(w, http.StatusConflict, "already following feed", nil)
+		return
+	}
+
+	addedBy, err := s.db.GetUserByID(r.Context(), feedRecord.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch user", err)
+		return
+	}
+
+	// an existing feed is followed, rather than added again
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, status, feedOutput{
+		ID:            feedRecord.ID.String(),
+		Name:          feedRecord.Name,
+		URL:           feedRecord.Url,
+		AddedBy:       addedBy.Name,
+		CreatedAt:     feedRecord.CreatedAt,
+		LastFetchedAt: optionalTime(feedRecord.LastFetchedAt.Time, feedRecord.LastFetchedAt.Valid),
+	})
+}
+
+// lists the feeds the user follows
+func apiGetFollows(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	feedFollowRecords, err := s.db.GetFeedFollowForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch follows", err)
+		return
+	}
+
+	followOutputs := []followOutput{}
+	for _, feedFollowRecord := range feedFollowRecords {
+		followOutputs = append(followOutputs, followOutput{
+			FeedID:     feedFollowRecord.FeedID.String(),
+			FeedName:   feedFollowRecord.FeedName,
+			FeedURL:    feedFollowRecord.FeedUrl,
+			Folder:     feedFollowRecord.FolderName.String,
+			FollowedAt: feedFollowRecord.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, followOutputs)
+}
+
+// follows an existing feed by its URL
+func apiCreateFollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	var params apiFollowRequest
+	if err := decodeRequest(r, &params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body", nil)
+		return
+	}
+
+	feedRecord, err := getFeedByURL(r.Context(), s.db, params.FeedURL)
+	if err == sql.ErrNoRows {
+		respondWithError(w, http.StatusNotFound, "feed not found", nil)
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch feed", err)
+		return
+	}
+
+	feedFollowRecord, err := s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedRecord.ID,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "already following feed", nil)
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to follow feed", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, followOutput{
+		FeedID:     feedRecord.ID.String(),
+		FeedName:   feedRecord.Name,
+		FeedURL:    feedRecord.Url,
+		FollowedAt: feedFollowRecord.CreatedAt,
+	})
+}
+
+// unfollows a feed by its ID
+func apiDeleteFollow(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed id", nil)
+		return
+	}
+
+	_, err = s.db.DeleteFeedFollowForUserURL(r.Context(), database.DeleteFeedFollowForUserURLParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err == sql.ErrNoRows {
+		respondWithError(w, http.StatusNotFound, "not following feed", nil)
+		return
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to unfollow feed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lists posts from the feeds the user follows.
+// accepts limit, offset, unread, feed_id, folder and sort query parameters.
+func apiGetPosts(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	params := database.BrowsePostsForUserParams{
+		UserID:    user.ID,
+		PostLimit: 10,
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > apiMaxPostLimit {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", nil)
+			return
+		}
+		params.PostLimit = int64(value)
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			respondWithError(w, http.StatusBadRequest, "offset must not be negative", nil)
+			return
+		}
+		params.PostOffset = int64(value)
+	}
+
+	if unread := query.Get("unread"); unread != "" {
+		value, err := strconv.ParseBool(unread)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "unread must be true or false", nil)
+			return
+		}
+		params.IncludeRead = !value
+	}
+
+	if feedID := query.Get("feed_id"); feedID != "" {
+		value, err := uuid.Parse(feedID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid feed id", nil)
+			return
+		}
+		params.FeedID = uuid.NullUUID{UUID: value, Valid: true}
+	}
+
+	if folder := query.Get("folder"); folder != "" {
+		folderRecord, err := s.db.GetFolderByName(r.Context(), database.GetFolderByNameParams{
+			UserID: user.ID,
+			Name:   folder,
+		})
+		if err == sql.ErrNoRows {
+			respondWithError(w, http.StatusNotFound, "folder not found", nil)
+			return
+		} else if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "unable to fetch folder", err)
+			return
+		}
+		params.FolderID = uuid.NullUUID{UUID: folderRecord.ID, Valid: true}
+	}
+
+	switch query.Get("sort") {
+	case "", "newest":
+	case "oldest":
+		params.OldestFirst = true
+	default:
+		respondWithError(w, http.StatusBadRequest, "sort must be newest or oldest", nil)
+		return
+	}
+
+	posts, err := s.db.BrowsePostsForUser(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch posts", err)
+		return
+	}
+
+	postOutputs := []postOutput{}
+	for _, post := range posts {
+		postOutputs = append(postOutputs, newPostOutput(post))
+	}
+
+	respondWithJSON(w, http.StatusOK, postOutputs)
+}
+
+// looks up the post from the postID path value, responding when it fails.
+// only posts from the feeds the user follows, or that they starred, are found.
+func apiGetPost(s *state, w http.ResponseWriter, r *http.Request, user database.User) (database.GetPostForUserRow, bool) {
+	postID, err := uuid.Parse(r.PathValue("postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid post id", nil)
+		return database.GetPostForUserRow{}, false
+	}
+
+	postRecord, err := s.db.GetPostForUser(r.Context(), database.GetPostForUserParams{
+		ID:     postID,
+		UserID: user.ID,
+	})
+	if err == sql.ErrNoRows {
+		respondWithError(w, http.StatusNotFound, "post not found", nil)
+		return database.GetPostForUserRow{}, false
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to fetch post", err)
+		return database.GetPostForUserRow{}, false
+	}
+
+	return postRecord, true
+}
+
+// marks a post as read for the user
+func apiMarkPostRead(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	postRecord, ok := apiGetPost(s, w, r, user)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	_, err := s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		PostID:    postRecord.ID,
+		ReadAt:    sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to mark post as read", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// marks a post as unread for the user
+func apiMarkPostUnread(s *state, w http.ResponseWriter, r *http.Request, user database.User) {
+	postRecord, ok := apiGetPost(s, w, r, user)
+	if !ok {
+		return
+	}
+
+	_, err := s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+		UserID:    user.ID,
+		PostID:    postRecord.ID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "unable to mark post as unread", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runs the API server until it fails
+func serveAPI(s *state, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newAPIMux(s),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Serving the gator API on %s\n", addr)
+	return server.ListenAndServe()
+}
@@ -20,15 +20,31 @@ inner join users
 select 
 	feed_follows.*,
 	feeds.name as feed_name,
-	users.name as user_name
+	feeds.url as feed_url,
+	users.name as user_name,
+	folders.name as folder_name
 from feed_follows
 inner join users
 	on feed_follows.user_id = users.id
 	and feed_follows.user_id = $1
 inner join feeds
-	on feed_follows.feed_id = feeds.id;
+	on feed_follows.feed_id = feeds.id
+left join folders
+	on feed_follows.folder_id = folders.id
+order by folders.name asc nulls first, feeds.name asc;
 
 -- name: DeleteFeedFollowForUserURL :one
 delete from feed_follows
 where user_id = $1 and feed_id = $2
 returning id, created_at, updated_at, user_id, feed_id;
+
+-- name: SetFeedFollowFolder :execrows
+update feed_follows
+set folder_id = $3,
+	updated_at = $4
+where user_id = $1 and feed_id = $2;
+
+-- name: GetFeedFollow :one
+select * from feed_follows
+where user_id = $1 and feed_id = $2
+limit 1;
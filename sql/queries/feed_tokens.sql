@@ -0,0 +1,20 @@
+-- name: DeleteFeedTokenForUser :exec
+delete from feed_tokens
+	where user_id = $1;
+
+-- name: SetFeedToken :exec
+insert into feed_tokens (
+	id, created_at, user_id, token_hash
+) values (
+	$1, $2, $3, $4
+)
+on conflict (user_id) do update
+set created_at = excluded.created_at,
+	token_hash = excluded.token_hash;
+
+-- name: GetUserByFeedToken :one
+select users.* from users
+	inner join feed_tokens
+	on feed_tokens.user_id = users.id
+	where feed_tokens.token_hash = $1
+	limit 1;
@@ -1,10 +1,10 @@
 -- name: CreateFeed :one
 insert into feeds (
-	id, name, created_at, updated_at, url, user_id
+	id, name, created_at, updated_at, url, user_id, fetch_interval, public_only
 ) values (
-	$1, $2, $3, $4, $5, $6
+	$1, $2, $3, $4, $5, $6, $7, $8
 	)
-returning id, name, created_at, updated_at, url, user_id;
+returning id, name, created_at, updated_at, url, user_id, fetch_interval, public_only;
 
 -- name: GetAllFeeds :many
 select * from feeds;
@@ -31,10 +31,92 @@ select * from feeds
 -- name: MarkFeedFetched :exec
 update feeds
 set last_fetched_at = $2,
-	updated_at = $2
+	updated_at = $2,
+	next_fetch_at = $3
 where id = $1;
 
 -- name: GetNextFeedToFetch :one
 select * from feeds
+	where disabled = false
+	and (next_fetch_at is null or next_fetch_at <= $1)
 	order by last_fetched_at asc nulls first
-	limit 1;
+	limit 1
+	for update skip locked;
+
+-- name: SetFeedCacheHeaders :exec
+update feeds
+set etag = $2,
+	last_modified = $3
+where id = $1;
+
+-- name: MarkFeedFailed :exec
+update feeds
+set consecutive_failures = consecutive_failures + 1,
+	last_error = $2,
+	last_error_at = $3,
+	next_fetch_at = $4
+where id = $1;
+
+-- name: MarkFeedSucceeded :exec
+update feeds
+set consecutive_failures = 0,
+	next_fetch_at = $2
+where id = $1;
+
+-- name: GetFailingFeeds :many
+select * from feeds
+	where consecutive_failures > 0
+	or disabled = true
+	order by consecutive_failures desc, last_error_at desc;
+
+-- name: DisableFeed :exec
+update feeds
+set disabled = true,
+	updated_at = $2
+where id = $1;
+
+-- name: EnableFeed :exec
+update feeds
+set disabled = false,
+	consecutive_failures = 0,
+	next_fetch_at = null,
+	updated_at = $2
+where id = $1;
+
+-- name: SetFeedInterval :exec
+update feeds
+set fetch_interval = $2,
+	updated_at = $3
+where id = $1;
+
+-- name: DeleteFeed :exec
+delete from feeds
+where id = $1;
+
+-- name: RenameFeed :exec
+update feeds
+set name = $2,
+	updated_at = $3
+where id = $1;
+
+-- name: SetFeedURL :exec
+update feeds
+set url = $2,
+	updated_at = $3,
+	etag = null,
+	last_modified = null,
+	consecutive_failures = 0,
+	last_error = null,
+	last_error_at = null,
+	next_fetch_at = null,
+	ttl = null,
+	skip_hours = null,
+	skip_days = null
+where id = $1;
+
+-- name: SetFeedScheduleHints :exec
+update feeds
+set ttl = $2,
+	skip_hours = $3,
+	skip_days = $4
+where id = $1;
@@ -0,0 +1,32 @@
+-- name: CreateFolder :one
+insert into folders (
+	id, created_at, updated_at, user_id, name
+) values (
+	$1, $2, $3, $4, $5
+) returning *;
+
+-- name: GetFolderByName :one
+select * from folders
+	where user_id = $1
+	and name = $2
+	limit 1;
+
+-- name: GetFoldersForUser :many
+select folders.*,
+	count(feed_follows.id) as feed_count
+	from folders
+	left join feed_follows
+	on feed_follows.folder_id = folders.id
+	where folders.user_id = $1
+	group by folders.id
+	order by folders.name;
+
+-- name: RenameFolder :exec
+update folders
+set name = $2,
+	updated_at = $3
+where id = $1;
+
+-- name: DeleteFolder :exec
+delete from folders
+where id = $1;
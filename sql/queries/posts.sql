@@ -1,15 +1,114 @@
--- name: CreatePost :one
-insert into posts (
-	id, created_at, updated_at, title, url, description, published_at, feed_id
-) values (
-	$1, $2, $3, $4, $5, $6, $7, $8
-) returning *;
+-- name: GetPostForUser :one
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink
+	from posts
+	where posts.id = sqlc.arg(id)
+	and (
+		exists (
+			select 1 from feed_follows
+			where feed_follows.feed_id = posts.feed_id
+			and feed_follows.user_id = sqlc.arg(user_id)
+		)
+		or exists (
+			select 1 from user_posts
+			where user_posts.post_id = posts.id
+			and user_posts.user_id = sqlc.arg(user_id)
+			and user_posts.starred = true
+		)
+	)
+	limit 1;
 
 -- name: GetPostsForUser :many
-select *
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink,
+	coalesce(user_posts.read, false) as read,
+	coalesce(user_posts.starred, false) as starred
+	from posts
+	inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+	left join user_posts
+	on user_posts.post_id = posts.id
+	and user_posts.user_id = feed_follows.user_id
+	where feed_follows.user_id = sqlc.arg(user_id)
+	and (sqlc.arg(include_read)::boolean or coalesce(user_posts.read, false) = false)
+	order by posts.published_at desc nulls last
+	limit sqlc.arg(post_limit);
+
+-- name: BrowsePostsForUser :many
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink,
+	feeds.name as feed_name,
+	coalesce(user_posts.read, false) as read,
+	coalesce(user_posts.starred, false) as starred
 	from posts
 	inner join feed_follows
 	on feed_follows.feed_id = posts.feed_id
-	where feed_follows.user_id = $1
-	order by published_at desc
-	limit $2;
+	inner join feeds
+	on feeds.id = posts.feed_id
+	left join user_posts
+	on user_posts.post_id = posts.id
+	and user_posts.user_id = feed_follows.user_id
+	where feed_follows.user_id = sqlc.arg(user_id)
+	and (sqlc.arg(include_read)::boolean or coalesce(user_posts.read, false) = false)
+	and (sqlc.narg(feed_id)::uuid is null or posts.feed_id = sqlc.narg(feed_id)::uuid)
+	and (sqlc.narg(folder_id)::uuid is null or feed_follows.folder_id = sqlc.narg(folder_id)::uuid)
+	and (sqlc.narg(since)::timestamp is null or posts.published_at >= sqlc.narg(since)::timestamp)
+	and (sqlc.narg(until)::timestamp is null or posts.published_at < sqlc.narg(until)::timestamp)
+	order by
+		case when sqlc.arg(oldest_first)::boolean then posts.published_at end asc nulls last,
+		case when not sqlc.arg(oldest_first)::boolean then posts.published_at end desc nulls last,
+		posts.id
+	limit sqlc.arg(post_limit)
+	offset sqlc.arg(post_offset);
+
+-- name: SearchPostsForUser :many
+select posts.id, posts.title, posts.url, posts.published_at,
+	feeds.name as feed_name,
+	ts_rank(posts.search_vector, search_query) as rank,
+	ts_headline('english', coalesce(posts.description, posts.title), search_query,
+		'StartSel=[, StopSel=], MaxFragments=2, MaxWords=25, MinWords=10') as snippet
+	from posts
+	inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+	inner join feeds
+	on feeds.id = posts.feed_id,
+	websearch_to_tsquery('english', sqlc.arg(search_terms)) as search_query
+	where feed_follows.user_id = sqlc.arg(user_id)
+	and posts.search_vector @@ search_query
+	order by rank desc, posts.published_at desc nulls last
+	limit sqlc.arg(post_limit);
+
+-- name: UpsertPost :one
+insert into posts (
+	id, created_at, updated_at, title, url, description, published_at, feed_id, author,
+	guid, guid_is_permalink
+) values (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+on conflict (feed_id, guid) do update
+set title = excluded.title,
+	url = excluded.url,
+	description = excluded.description,
+	published_at = coalesce(excluded.published_at, posts.published_at),
+	author = excluded.author,
+	guid_is_permalink = excluded.guid_is_permalink,
+	updated_at = excluded.updated_at
+where (posts.title, posts.url, posts.description, posts.author)
+	is distinct from (excluded.title, excluded.url, excluded.description, excluded.author)
+returning id, (xmax = 0)::boolean as inserted;
+
+-- name: SetPostGUIDByURL :execrows
+update posts
+set guid = sqlc.arg(guid),
+	guid_is_permalink = sqlc.arg(guid_is_permalink)
+where posts.feed_id = sqlc.arg(feed_id)
+and posts.url = sqlc.arg(url)
+and posts.guid = posts.url
+and not exists (
+	select 1 from posts as known
+	where known.feed_id = sqlc.arg(feed_id)
+	and known.guid = sqlc.arg(guid)
+);
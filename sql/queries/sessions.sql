@@ -0,0 +1,27 @@
+-- name: CreateSession :one
+insert into sessions (
+	id, created_at, expires_at, user_id, token_hash
+) values (
+	$1, $2, $3, $4, $5
+) returning *;
+
+-- name: GetUserBySessionToken :one
+select users.* from users
+	inner join sessions
+	on sessions.user_id = users.id
+	where sessions.token_hash = sqlc.arg(token_hash)
+	and sessions.expires_at > sqlc.arg(now)::timestamp
+	limit 1;
+
+-- name: DeleteSession :exec
+delete from sessions
+	where token_hash = $1;
+
+-- name: DeleteExpiredSessions :exec
+delete from sessions
+	where expires_at <= $1;
+
+-- name: DeleteSessionsForUser :execrows
+delete from sessions
+	where user_id = sqlc.arg(user_id)
+	and token_hash <> sqlc.arg(keep_token_hash);
@@ -0,0 +1,102 @@
+-- name: MarkPostRead :execrows
+insert into user_posts (
+	id, created_at, updated_at, user_id, post_id, read, read_at
+) values (
+	$1, $2, $3, $4, $5, true, $6
+)
+on conflict (user_id, post_id) do update
+set read = true,
+	read_at = excluded.read_at,
+	updated_at = excluded.updated_at;
+
+-- name: MarkAllPostsRead :execrows
+insert into user_posts (
+	id, created_at, updated_at, user_id, post_id, read, read_at
+) select
+	gen_random_uuid(), sqlc.arg(read_at)::timestamp, sqlc.arg(read_at)::timestamp,
+	feed_follows.user_id, posts.id, true, sqlc.arg(read_at)::timestamp
+from posts
+inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+where feed_follows.user_id = sqlc.arg(user_id)
+on conflict (user_id, post_id) do update
+set read = true,
+	read_at = excluded.read_at,
+	updated_at = excluded.updated_at
+where user_posts.read = false;
+
+-- name: MarkFeedPostsRead :execrows
+insert into user_posts (
+	id, created_at, updated_at, user_id, post_id, read, read_at
+) select
+	gen_random_uuid(), sqlc.arg(read_at)::timestamp, sqlc.arg(read_at)::timestamp,
+	feed_follows.user_id, posts.id, true, sqlc.arg(read_at)::timestamp
+from posts
+inner join feed_follows
+	on feed_follows.feed_id = posts.feed_id
+where feed_follows.user_id = sqlc.arg(user_id)
+and posts.feed_id = sqlc.arg(feed_id)
+on conflict (user_id, post_id) do update
+set read = true,
+	read_at = excluded.read_at,
+	updated_at = excluded.updated_at
+where user_posts.read = false;
+
+-- name: MarkPostUnread :execrows
+update user_posts
+set read = false,
+	read_at = null,
+	updated_at = $3
+where user_id = $1 and post_id = $2
+and read = true;
+
+-- name: MarkAllPostsUnread :execrows
+update user_posts
+set read = false,
+	read_at = null,
+	updated_at = $2
+where user_id = $1
+and read = true;
+
+-- name: MarkFeedPostsUnread :execrows
+update user_posts
+set read = false,
+	read_at = null,
+	updated_at = $2
+where user_id = $1
+and read = true
+and post_id in (
+	select id from posts
+	where feed_id = $3
+);
+
+-- name: StarPost :execrows
+insert into user_posts (
+	id, created_at, updated_at, user_id, post_id, starred, starred_at
+) values (
+	$1, $2, $3, $4, $5, true, $6
+)
+on conflict (user_id, post_id) do update
+set starred = true,
+	starred_at = excluded.starred_at,
+	updated_at = excluded.updated_at
+where user_posts.starred = false;
+
+-- name: UnstarPost :execrows
+update user_posts
+set starred = false,
+	starred_at = null,
+	updated_at = $3
+where user_id = $1 and post_id = $2
+and starred = true;
+
+-- name: GetStarredPostsForUser :many
+select posts.id, posts.created_at, posts.updated_at, posts.title, posts.url,
+	posts.description, posts.published_at, posts.feed_id, posts.author,
+	posts.guid, posts.guid_is_permalink, user_posts.starred_at
+	from posts
+	inner join user_posts
+	on user_posts.post_id = posts.id
+	where user_posts.user_id = $1
+	and user_posts.starred = true
+	order by user_posts.starred_at desc;
@@ -1,9 +1,12 @@
+-- name: CountUsers :one
+select count(*) from users;
+
 -- name: CreateUser :one
 insert into users (
-	id, created_at, updated_at, name
+	id, created_at, updated_at, name, hashed_password, is_admin
 ) values (
-	$1, $2, $3, $4
-) returning id, created_at, updated_at, name;
+	$1, $2, $3, $4, $5, $6
+) returning id, created_at, updated_at, name, is_admin;
 
 -- name: GetUserByName :one
 select * from users
@@ -20,3 +23,15 @@ select * from users;
 
 -- name: ResetUsers :exec
 delete from users;
+
+-- name: SetUserPassword :exec
+update users
+	set hashed_password = $2,
+	updated_at = $3
+	where id = $1;
+
+-- name: SetUserAdmin :exec
+update users
+	set is_admin = $2,
+	updated_at = $3
+	where id = $1;
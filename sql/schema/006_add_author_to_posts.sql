@@ -0,0 +1,7 @@
+-- +goose Up
+alter table posts
+add column author text;
+
+-- +goose Down
+alter table posts
+drop column author;
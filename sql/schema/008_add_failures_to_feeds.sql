@@ -0,0 +1,11 @@
+-- +goose Up
+alter table feeds
+add column consecutive_failures integer not null default 0,
+add column last_error text,
+add column last_error_at timestamp;
+
+-- +goose Down
+alter table feeds
+drop column consecutive_failures,
+drop column last_error,
+drop column last_error_at;
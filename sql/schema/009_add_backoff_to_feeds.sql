@@ -0,0 +1,9 @@
+-- +goose Up
+alter table feeds
+add column disabled boolean not null default false,
+add column next_fetch_at timestamp;
+
+-- +goose Down
+alter table feeds
+drop column disabled,
+drop column next_fetch_at;
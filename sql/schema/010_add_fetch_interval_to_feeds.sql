@@ -0,0 +1,7 @@
+-- +goose Up
+alter table feeds
+add column fetch_interval integer;
+
+-- +goose Down
+alter table feeds
+drop column fetch_interval;
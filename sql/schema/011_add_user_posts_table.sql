@@ -0,0 +1,27 @@
+-- +goose Up
+create table user_posts (
+	id uuid primary key,
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	user_id uuid not null,
+	post_id uuid not null,
+	read boolean not null default false,
+	read_at timestamp,
+
+	unique(user_id, post_id)
+);
+
+alter table user_posts
+	add constraint fk_user
+	foreign key (user_id)
+	references users(id)
+	on delete cascade;
+
+alter table user_posts
+	add constraint fk_post
+	foreign key (post_id)
+	references posts(id)
+	on delete cascade;
+
+-- +goose Down
+drop table user_posts;
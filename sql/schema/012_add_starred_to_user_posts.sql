@@ -0,0 +1,9 @@
+-- +goose Up
+alter table user_posts
+add column starred boolean not null default false,
+add column starred_at timestamp;
+
+-- +goose Down
+alter table user_posts
+drop column starred,
+drop column starred_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+alter table posts
+add column search_vector tsvector
+	generated always as (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'B')
+	) stored;
+
+create index posts_search_vector_idx
+	on posts
+	using gin (search_vector);
+
+-- +goose Down
+drop index posts_search_vector_idx;
+
+alter table posts
+drop column search_vector;
@@ -0,0 +1,23 @@
+-- +goose Up
+alter table users
+	add column hashed_password text;
+
+create table sessions (
+	id uuid primary key,
+	created_at timestamp not null,
+	expires_at timestamp not null,
+	user_id uuid not null,
+	token_hash text not null unique
+);
+
+alter table sessions
+	add constraint fk_user
+	foreign key (user_id)
+	references users(id)
+	on delete cascade;
+
+-- +goose Down
+drop table sessions;
+
+alter table users
+	drop column hashed_password;
@@ -0,0 +1,16 @@
+-- +goose Up
+alter table users
+	add column is_admin boolean not null default false;
+
+-- the first user to register becomes the admin of existing databases
+update users
+	set is_admin = true
+	where id = (
+		select id from users
+		order by created_at asc
+		limit 1
+	);
+
+-- +goose Down
+alter table users
+	drop column is_admin;
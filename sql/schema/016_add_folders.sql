@@ -0,0 +1,32 @@
+-- +goose Up
+create table folders (
+	id uuid primary key,
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	user_id uuid not null,
+	name text not null,
+
+	unique(user_id, name)
+);
+
+alter table folders
+	add constraint fk_user
+	foreign key (user_id)
+	references users(id)
+	on delete cascade;
+
+-- deleting a folder leaves its feeds followed, without a folder
+alter table feed_follows
+	add column folder_id uuid;
+
+alter table feed_follows
+	add constraint fk_folder
+	foreign key (folder_id)
+	references folders(id)
+	on delete set null;
+
+-- +goose Down
+alter table feed_follows
+	drop column folder_id;
+
+drop table folders;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- the guid of an item, or its link when the item has none
+alter table posts
+	add column guid text,
+	add column guid_is_permalink boolean;
+
+update posts
+	set guid = url;
+
+alter table posts
+	alter column guid set not null;
+
+-- posts are unique within their feed, so feeds may link to the same article
+alter table posts
+	drop constraint posts_url_key;
+
+alter table posts
+	add constraint posts_feed_id_guid_key
+	unique (feed_id, guid);
+
+-- +goose Down
+alter table posts
+	drop constraint posts_feed_id_guid_key;
+
+alter table posts
+	add constraint posts_url_key
+	unique (url);
+
+alter table posts
+	drop column guid,
+	drop column guid_is_permalink;
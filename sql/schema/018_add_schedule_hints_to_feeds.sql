@@ -0,0 +1,13 @@
+-- +goose Up
+-- the ttl, skipHours and skipDays of the last fetched document,
+-- so that they still apply when the feed has not been modified
+alter table feeds
+	add column ttl integer,
+	add column skip_hours text,
+	add column skip_days text;
+
+-- +goose Down
+alter table feeds
+	drop column ttl,
+	drop column skip_hours,
+	drop column skip_days;
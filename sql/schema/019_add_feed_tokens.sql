@@ -0,0 +1,17 @@
+-- +goose Up
+-- read-only tokens for the published feed of a user, one per user
+create table feed_tokens (
+	id uuid primary key,
+	created_at timestamp not null,
+	user_id uuid not null unique,
+	token_hash text not null unique
+);
+
+alter table feed_tokens
+	add constraint fk_user
+	foreign key (user_id)
+	references users(id)
+	on delete cascade;
+
+-- +goose Down
+drop table feed_tokens;
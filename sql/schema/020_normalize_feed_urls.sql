@@ -0,0 +1,59 @@
+-- +goose Up
+-- feed URLs are normalized when they are added, this does the same for existing feeds.
+-- like normalizeURL, the scheme and host are lowercased, default ports and fragments
+-- are removed, and tracking parameters are removed with the rest sorted by name.
+-- +goose StatementBegin
+create function normalize_feed_url(raw_url text) returns text
+language sql immutable as $$
+	select case
+		when parts is null or lower(parts[1]) not in ('http', 'https') then trim(raw_url)
+		else lower(parts[1]) || '://'
+			|| case lower(parts[1])
+				when 'http' then regexp_replace(lower(parts[2]), ':80$', '')
+				else regexp_replace(lower(parts[2]), ':443$', '')
+			end
+			|| case when parts[3] = '' then '/' else parts[3] end
+			|| coalesce('?' || (
+				select string_agg(param, '&' order by split_part(param, '=', 1) collate "C", ordinal)
+				from regexp_split_to_table(coalesce(substr(parts[4], 2), ''), '&')
+					with ordinality as params(param, ordinal)
+				where param <> ''
+				and lower(split_part(param, '=', 1)) not like 'utm\_%'
+				and lower(split_part(param, '=', 1)) not in (
+					'fbclid', 'gclid', 'dclid', 'msclkid', 'yclid',
+					'igshid', 'mc_cid', 'mc_eid', '_hsenc', '_hsmi'
+				)
+			), '')
+	end
+	from (
+		select regexp_match(
+			trim(raw_url),
+			'^([A-Za-z][A-Za-z0-9+.-]*)://([^/?#]+)([^?#]*)(\?[^#]*)?(#.*)?$'
+		) as parts
+	) as matched
+$$;
+-- +goose StatementEnd
+
+-- feeds that would normalize to the URL of another feed are left as they are
+with normalized as (
+	select id,
+		normalize_feed_url(url) as url,
+		row_number() over (partition by normalize_feed_url(url) order by created_at) as duplicate
+	from feeds
+)
+update feeds
+set url = normalized.url
+from normalized
+where feeds.id = normalized.id
+and normalized.duplicate = 1
+and feeds.url <> normalized.url
+and not exists (
+	select 1 from feeds as existing
+	where existing.url = normalized.url
+);
+
+drop function normalize_feed_url(text);
+
+-- +goose Down
+-- the URLs the feeds were added with are not kept, so they stay normalized
+select 1;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- feeds added through the API are only fetched from public addresses,
+-- as their URLs come from callers who should not reach the servers network
+alter table feeds
+	add column public_only boolean not null default false;
+
+-- +goose Down
+alter table feeds
+	drop column public_only;
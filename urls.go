@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/nicholasss/gator/internal/database"
+)
+
+// ports that are left out of URLs, as they are the default of the scheme
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// query parameters that only track where a visit came from, utm_* parameters are also removed
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+}
+
+// =============
+// URL FUNCTIONS
+// =============
+
+// canonicalizes an http or https URL of a feed, so that equivalent URLs compare as equal.
+// the scheme and host are lowercased, default ports and fragments are removed,
+// tracking parameters are removed and the remaining query parameters are sorted.
+// other URLs, and those that cannot be parsed, are returned trimmed but unchanged.
+func normalizeURL(rawURL string) string {
+	return canonicalURL(rawURL, false)
+}
+
+// canonicalizes the link of a post like normalizeURL, but keeps its fragment,
+// as posts in the same feed may link to different parts of one page
+func normalizePostURL(rawURL string) string {
+	return canonicalURL(rawURL, true)
+}
+
+// canonicalizes an http or https URL, optionally keeping its fragment
+func canonicalURL(rawURL string, keepFragment bool) string {
+	rawURL = strings.TrimSpace(rawURL)
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return rawURL
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	port, ok := defaultPorts[parsedURL.Scheme]
+	if !ok {
+		return rawURL
+	}
+
+	parsedURL.Host = strings.TrimSuffix(strings.ToLower(parsedURL.Host), ":"+port)
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+	}
+	if !keepFragment {
+		parsedURL.Fragment = ""
+		parsedURL.RawFragment = ""
+	}
+	parsedURL.RawQuery = normalizeQuery(parsedURL.RawQuery)
+	parsedURL.ForceQuery = false
+
+	return parsedURL.String()
+}
+
+// removes tracking parameters from a raw query, and sorts the rest by name.
+// parameters keep their original encoding, and repeated names keep their order.
+func normalizeQuery(rawQuery string) string {
+	type param struct {
+		name string
+		raw  string
+	}
+
+	var params []param
+	for _, rawParam := range strings.Split(rawQuery, "&") {
+		if rawParam == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(rawParam, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if isTrackingParam(name) {
+			continue
+		}
+
+		params = append(params, param{name: name, raw: rawParam})
+	}
+
+	slices.SortStableFunc(params, func(a, b param) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	rawParams := make([]string, 0, len(params))
+	for _, p := range params {
+		rawParams = append(rawParams, p.raw)
+	}
+	return strings.Join(rawParams, "&")
+}
+
+// checks if a query parameter is only used for tracking
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// returns the URLs that are treated as the same feed, with the normalized URL first.
+// the other scheme and a toggled trailing slash are included, as either may be
+// served by a site, and so is the URL as given, for feeds added before normalization.
+func urlVariants(rawURL string) []string {
+	normalized := normalizeURL(rawURL)
+	variants := []string{normalized}
+
+	parsedURL, err := url.Parse(normalized)
+	if err == nil && parsedURL.Host != "" && defaultPorts[parsedURL.Scheme] != "" {
+		alternate := *parsedURL
+		alternate.Scheme = "https"
+		if parsedURL.Scheme == "https" {
+			alternate.Scheme = "http"
+		}
+		variants = append(variants, alternate.String())
+
+		for _, variant := range []url.URL{*parsedURL, alternate} {
+			if variant.Path == "/" {
+				continue
+			}
+
+			// the raw path keeps escaped slashes, so it is changed along with the path
+			if strings.HasSuffix(variant.Path, "/") {
+				variant.Path = strings.TrimSuffix(variant.Path, "/")
+				variant.RawPath = strings.TrimSuffix(variant.RawPath, "/")
+			} else {
+				variant.Path += "/"
+				if variant.RawPath != "" {
+					variant.RawPath += "/"
+				}
+			}
+			variants = append(variants, variant.String())
+		}
+	}
+
+	if trimmed := strings.TrimSpace(rawURL); !slices.Contains(variants, trimmed) {
+		variants = append(variants, trimmed)
+	}
+
+	return variants
+}
+
+// looks up a feed by its URL, also matching the variants of the URL.
+// sql.ErrNoRows is returned when no feed matches.
+func getFeedByURL(ctx context.Context, q *database.Queries, rawURL string) (database.Feed, error) {
+	for _, variant := range urlVariants(rawURL) {
+		feedRecord, err := q.GetFeedByURL(ctx, variant)
+		if err != sql.ErrNoRows {
+			return feedRecord, err
+		}
+	}
+
+	return database.Feed{}, sql.ErrNoRows
+}